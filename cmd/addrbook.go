@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/constants"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"net"
+	"os"
+	"path"
+	"strconv"
+)
+
+const (
+	flagPrune = "prune"
+
+	// maxAddrBookFileSize is about twice the size of a full address book, 256 new & 64 old buckets of 64 addresses.
+	maxAddrBookFileSize = 16 * 1024 * 1024
+)
+
+// addrBookProblem is the reason an entry of the address book should be pruned.
+type addrBookProblem string
+
+const (
+	addrBookProblemOwnId   addrBookProblem = "own node ID"
+	addrBookProblemPrivate addrBookProblem = "private address"
+	addrBookProblemBogus   addrBookProblem = "bogus address"
+)
+
+// addrBookReport summarizes the address book.
+type addrBookReport struct {
+	countNew            int
+	countOld            int
+	countNeverSucceeded int
+	countByProblem      map[addrBookProblem]int
+	badEntries          []addrBookBadEntry
+}
+
+type addrBookBadEntry struct {
+	index   int
+	entry   types.KnownAddress
+	problem addrBookProblem
+	detail  string
+}
+
+func getPeersAddrBookCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "addrbook [home]",
+		Short: "Report health of config/addrbook.json, preview pruning of bad entries",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+			prune, _ := cmd.Flags().GetBool(flagPrune)
+			privateNetwork, _ := cmd.Flags().GetBool(flagPrivateNetwork)
+
+			addrBookFilePath := path.Join(home, "config", "addrbook.json")
+			perm, exists, isDir, err := utils.FileInfo(addrBookFilePath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to check addrbook.json file at %s: %v\n", addrBookFilePath, err)
+				return
+			}
+			if !exists {
+				fmt.Println("addrbook.json does not exist, node has not discovered any peer")
+				return
+			}
+			if isDir {
+				exitWithErrorMsgf("ERR: addrbook.json is a directory, it should be a file: %s\n", addrBookFilePath)
+				return
+			}
+
+			bz, err := os.ReadFile(addrBookFilePath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to read addrbook.json file at %s: %v\n", addrBookFilePath, err)
+				return
+			}
+			addrBook, err := unmarshalAddrBook(bz)
+			if err != nil {
+				exitWithErrorMsgf("ERR: %v\n", err)
+				return
+			}
+
+			nodeId := readNodeIdOrEmpty(home)
+			report := inspectAddrBook(addrBook, nodeId, privateNetwork)
+
+			fmt.Println("File:", addrBookFilePath)
+			fmt.Printf("- size: %s\n", formatBytes(int64(len(bz))))
+			fmt.Printf("- permission: %s\n", perm.String())
+			fmt.Println("Entries:", len(addrBook.Addrs))
+			fmt.Printf("- new bucket: %d\n", report.countNew)
+			fmt.Printf("- old bucket: %d\n", report.countOld)
+			fmt.Printf("- never connected successfully: %d\n", report.countNeverSucceeded)
+			if nodeId == "" {
+				fmt.Println("- own node ID: unknown, node_key.json is not readable")
+			} else {
+				fmt.Printf("- own node ID: %d\n", report.countByProblem[addrBookProblemOwnId])
+			}
+			if privateNetwork {
+				fmt.Printf("- private: %d (allowed by --%s)\n", report.countByProblem[addrBookProblemPrivate], flagPrivateNetwork)
+			} else {
+				fmt.Printf("- private: %d\n", report.countByProblem[addrBookProblemPrivate])
+			}
+			fmt.Printf("- bogus: %d\n", report.countByProblem[addrBookProblemBogus])
+
+			if len(report.badEntries) == 0 {
+				fmt.Println("No bad entry")
+				return
+			}
+			if !prune {
+				fmt.Printf("Found %d bad entries, provide --%s to preview pruning\n", len(report.badEntries), flagPrune)
+				return
+			}
+
+			fmt.Printf("\nPrune preview, %d of %d entries will be removed:\n", len(report.badEntries), len(addrBook.Addrs))
+			for _, bad := range report.badEntries {
+				fmt.Printf("- %s@%s: %s, %s\n", bad.entry.Addr.Id, net.JoinHostPort(bad.entry.Addr.Ip, strconv.Itoa(int(bad.entry.Addr.Port))), bad.problem, bad.detail)
+			}
+
+			const filePrunedAddrBook = "addrbook.pruned.json"
+			checkGeneratedFileNotExists(filePrunedAddrBook)
+			prunedBz, err := pruneAddrBook(bz, report.badEntries)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to prune address book: %v\n", err)
+				return
+			}
+			if err := os.WriteFile(filePrunedAddrBook, prunedBz, 0o600); err != nil {
+				exitWithErrorMsgf("ERR: failed to write %s: %v\n", filePrunedAddrBook, err)
+				return
+			}
+
+			fmt.Printf("\nPruned address book is written to %s, addrbook.json is not modified\n", filePrunedAddrBook)
+			fmt.Println("To apply, stop the node, then:")
+			fmt.Printf("- cp %s %s\n", filePrunedAddrBook, addrBookFilePath)
+		},
+	}
+
+	cmd.Flags().Bool(flagPrune, false, "preview removal of bad entries, the pruned address book is written to the current directory")
+	cmd.Flags().Bool(flagPrivateNetwork, false, "nodes are connected via private network, private addresses are not bad entries")
+
+	return cmd
+}
+
+func unmarshalAddrBook(bz []byte) (*types.AddrBook, error) {
+	var addrBook types.AddrBook
+	if err := json.Unmarshal(bz, &addrBook); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal addrbook.json")
+	}
+	return &addrBook, nil
+}
+
+// inspectAddrBook counts entries by bucket and finds bad entries.
+func inspectAddrBook(addrBook *types.AddrBook, nodeId string, privateNetwork bool) addrBookReport {
+	report := addrBookReport{
+		countByProblem: make(map[addrBookProblem]int),
+	}
+	for i, entry := range addrBook.Addrs {
+		switch entry.BucketType {
+		case types.AddrBookBucketTypeNew:
+			report.countNew++
+		case types.AddrBookBucketTypeOld:
+			report.countOld++
+		}
+		if entry.NeverSucceeded() {
+			report.countNeverSucceeded++
+		}
+
+		problem, detail := addrBookEntryProblem(entry.Addr, nodeId, privateNetwork)
+		if problem == "" {
+			continue
+		}
+		report.countByProblem[problem]++
+		report.badEntries = append(report.badEntries, addrBookBadEntry{
+			index:   i,
+			entry:   entry,
+			problem: problem,
+			detail:  detail,
+		})
+	}
+	return report
+}
+
+func addrBookEntryProblem(addr types.AddrBookNetAddress, nodeId string, privateNetwork bool) (addrBookProblem, string) {
+	if bz, err := hex.DecodeString(addr.Id); err != nil || len(bz) != 20 {
+		return addrBookProblemBogus, "invalid node ID"
+	}
+	if nodeId != "" && addr.Id == nodeId {
+		return addrBookProblemOwnId, "node would dial itself"
+	}
+	ip := net.ParseIP(addr.Ip)
+	if ip == nil {
+		return addrBookProblemBogus, "invalid IP"
+	}
+	if addr.Port == 0 {
+		return addrBookProblemBogus, "port 0"
+	}
+	if ip.IsUnspecified() || ip.IsMulticast() || ip.Equal(net.IPv4bcast) {
+		return addrBookProblemBogus, "not dialable IP"
+	}
+	if !privateNetwork && isNonPublicIP(ip) {
+		return addrBookProblemPrivate, "not reachable from the internet"
+	}
+	return "", ""
+}
+
+// pruneAddrBook removes the bad entries, other content is kept as is.
+func pruneAddrBook(bz []byte, badEntries []addrBookBadEntry) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &raw); err != nil {
+		return nil, err
+	}
+	var addrs []json.RawMessage
+	if err := json.Unmarshal(raw["addrs"], &addrs); err != nil {
+		return nil, err
+	}
+
+	isBad := make(map[int]bool)
+	for _, bad := range badEntries {
+		isBad[bad.index] = true
+	}
+	keptAddrs := make([]json.RawMessage, 0, len(addrs))
+	for i, addr := range addrs {
+		if !isBad[i] {
+			keptAddrs = append(keptAddrs, addr)
+		}
+	}
+
+	keptBz, err := json.Marshal(keptAddrs)
+	if err != nil {
+		return nil, err
+	}
+	raw["addrs"] = keptBz
+	return json.MarshalIndent(raw, "", "\t")
+}
+
+// readNodeIdOrEmpty returns node ID of the home, empty when node_key.json is missing or invalid.
+func readNodeIdOrEmpty(home string) string {
+	value, err := readPrivKeyValue(path.Join(home, "config", "node_key.json"))
+	if err != nil {
+		return ""
+	}
+	privKey, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+	nodeId, err := utils.NodeId(utils.PrivKeyTypeEd25519, privKey)
+	if err != nil {
+		return ""
+	}
+	return nodeId
+}
+
+// checkHomeConfigAddrBook checks addrbook.json, validator behind sentries should not have peers other than the sentries.
+func checkHomeConfigAddrBook(configPath string, nodeType types.NodeType, configToml *types.ConfigToml, nodeId string, privateNetwork bool) {
+	addrBookFilePath := path.Join(configPath, "addrbook.json")
+	perm, exists, isDir, err := utils.FileInfo(addrBookFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check addrbook.json file at %s: %v\n", addrBookFilePath, err)
+		return
+	}
+	if !exists {
+		return
+	}
+	if isDir {
+		exitWithErrorMsgf("ERR: addrbook.json is a directory, it should be a file: %s\n", addrBookFilePath)
+		return
+	}
+	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("addrbook.json", addrBookFilePath, "permission"))
+	if filePerm.Other.Write {
+		fatalRecord(fileTarget("addrbook.json", addrBookFilePath, "permission"), "addrbook.json file is writable by others", "chmod 644 "+addrBookFilePath)
+	}
+	if filePerm.Group.Write {
+		fatalRecord(fileTarget("addrbook.json", addrBookFilePath, "permission"), "addrbook.json file is writable by group", "chmod 644 "+addrBookFilePath)
+	}
+
+	bz, err := os.ReadFile(addrBookFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read addrbook.json file at %s: %v\n", addrBookFilePath, err)
+		return
+	}
+	evaluatedRule(fileTarget("addrbook.json", addrBookFilePath, "addrbook"))
+	if len(bz) > maxAddrBookFileSize {
+		warnRecord(
+			fileTarget("addrbook.json", addrBookFilePath, "addrbook"),
+			fmt.Sprintf("addrbook.json is %s, too large for an address book", formatBytes(int64(len(bz)))),
+			"stop the node and remove addrbook.json, it will be rebuilt from seeds",
+		)
+	}
+	addrBook, err := unmarshalAddrBook(bz)
+	if err != nil {
+		warnRecord(fileTarget("addrbook.json", addrBookFilePath, "addrbook"), err.Error(), "stop the node and remove addrbook.json, it will be rebuilt from seeds")
+		return
+	}
+
+	if nodeType == types.ValidatorNode && configToml.P2P.Pex != nil && !*configToml.P2P.Pex && len(addrBook.Addrs) > 0 {
+		warnRecord(
+			fileTarget("addrbook.json", addrBookFilePath, "addrbook"),
+			fmt.Sprintf("pex is disabled, validator is behind sentries but addrbook.json has %d entries, can be dialed if pex is enabled by mistake", len(addrBook.Addrs)),
+			"stop the node and remove addrbook.json",
+		)
+	}
+
+	report := inspectAddrBook(addrBook, nodeId, privateNetwork)
+	if len(report.badEntries) > 0 {
+		warnRecord(
+			fileTarget("addrbook.json", addrBookFilePath, "addrbook"),
+			fmt.Sprintf(
+				"addrbook.json has %d bad entries: %d own node ID, %d private, %d bogus",
+				len(report.badEntries), report.countByProblem[addrBookProblemOwnId], report.countByProblem[addrBookProblemPrivate], report.countByProblem[addrBookProblemBogus],
+			),
+			fmt.Sprintf("%s peers addrbook %s --%s", constants.BINARY_NAME, path.Dir(configPath), flagPrune),
+		)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"strings"
+	"testing"
+)
+
+const testAddrBookNodeId = "65b60673d6ed884bf01c2c222d82ada0740f29ac"
+
+func TestAddrBookEntryProblem(t *testing.T) {
+	const validId = "1111111111111111111111111111111111111111"
+	tests := []struct {
+		name           string
+		addr           types.AddrBookNetAddress
+		privateNetwork bool
+		want           addrBookProblem
+	}{
+		{name: "public", addr: types.AddrBookNetAddress{Id: validId, Ip: "8.8.8.8", Port: 26656}},
+		{name: "public IPv6", addr: types.AddrBookNetAddress{Id: validId, Ip: "2001:4860:4860::8888", Port: 26656}},
+		{name: "own node ID", addr: types.AddrBookNetAddress{Id: testAddrBookNodeId, Ip: "8.8.8.8", Port: 26656}, want: addrBookProblemOwnId},
+		{name: "private", addr: types.AddrBookNetAddress{Id: validId, Ip: "10.0.0.1", Port: 26656}, want: addrBookProblemPrivate},
+		{name: "loopback", addr: types.AddrBookNetAddress{Id: validId, Ip: "127.0.0.1", Port: 26656}, want: addrBookProblemPrivate},
+		{name: "private in private network", addr: types.AddrBookNetAddress{Id: validId, Ip: "10.0.0.1", Port: 26656}, privateNetwork: true},
+		{name: "invalid node ID", addr: types.AddrBookNetAddress{Id: "zz", Ip: "8.8.8.8", Port: 26656}, want: addrBookProblemBogus},
+		{name: "short node ID", addr: types.AddrBookNetAddress{Id: "1111", Ip: "8.8.8.8", Port: 26656}, want: addrBookProblemBogus},
+		{name: "invalid IP", addr: types.AddrBookNetAddress{Id: validId, Ip: "node.example.com", Port: 26656}, want: addrBookProblemBogus},
+		{name: "port 0", addr: types.AddrBookNetAddress{Id: validId, Ip: "8.8.8.8", Port: 0}, want: addrBookProblemBogus},
+		{name: "unspecified", addr: types.AddrBookNetAddress{Id: validId, Ip: "0.0.0.0", Port: 26656}, want: addrBookProblemBogus},
+		{name: "multicast", addr: types.AddrBookNetAddress{Id: validId, Ip: "224.0.0.1", Port: 26656}, want: addrBookProblemBogus},
+		{name: "broadcast", addr: types.AddrBookNetAddress{Id: validId, Ip: "255.255.255.255", Port: 26656}, want: addrBookProblemBogus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := addrBookEntryProblem(tt.addr, testAddrBookNodeId, tt.privateNetwork)
+			if got != tt.want {
+				t.Errorf("addrBookEntryProblem(%+v) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+// testAddrBook has fields which are not in types.AddrBook, they must be kept by pruning.
+const testAddrBook = `{
+	"key": "abcdef0123456789abcdef01",
+	"addrs": [
+		{"addr":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"src":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"buckets":[1],"attempts":0,"bucket_type":2,"last_attempt":"2024-01-01T00:00:00Z","last_success":"2024-01-01T00:00:00Z","last_ban_time":"0001-01-01T00:00:00Z"},
+		{"addr":{"id":"2222222222222222222222222222222222222222","ip":"10.0.0.1","port":26656},"src":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"buckets":[2],"attempts":3,"bucket_type":1,"last_attempt":"2024-01-01T00:00:00Z","last_success":"0001-01-01T00:00:00Z","last_ban_time":"0001-01-01T00:00:00Z"},
+		{"addr":{"id":"3333333333333333333333333333333333333333","ip":"1.1.1.1","port":26656},"src":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"buckets":[3],"attempts":0,"bucket_type":1,"last_attempt":"0001-01-01T00:00:00Z","last_success":"0001-01-01T00:00:00Z","last_ban_time":"2024-02-01T00:00:00Z"},
+		{"addr":{"id":"65b60673d6ed884bf01c2c222d82ada0740f29ac","ip":"1.2.3.4","port":26656},"src":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"buckets":[4],"attempts":0,"bucket_type":1,"last_attempt":"0001-01-01T00:00:00Z","last_success":"0001-01-01T00:00:00Z","last_ban_time":"0001-01-01T00:00:00Z"},
+		{"addr":{"id":"4444444444444444444444444444444444444444","ip":"5.6.7.8","port":0},"src":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"buckets":[5],"attempts":0,"bucket_type":1,"last_attempt":"0001-01-01T00:00:00Z","last_success":"0001-01-01T00:00:00Z","last_ban_time":"0001-01-01T00:00:00Z"},
+		{"addr":{"id":"5555555555555555555555555555555555555555","ip":"9.9.9.9","port":26656},"src":{"id":"1111111111111111111111111111111111111111","ip":"8.8.8.8","port":26656},"buckets":[6],"attempts":1,"bucket_type":2,"last_attempt":"2024-01-01T00:00:00Z","last_success":"2024-01-01T00:00:00Z","last_ban_time":"0001-01-01T00:00:00Z"}
+	]
+}`
+
+func TestInspectAddrBook(t *testing.T) {
+	addrBook, err := unmarshalAddrBook([]byte(testAddrBook))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := inspectAddrBook(addrBook, testAddrBookNodeId, false)
+	if report.countNew != 4 || report.countOld != 2 || report.countNeverSucceeded != 4 {
+		t.Errorf("new = %d, old = %d, never succeeded = %d, want 4, 2, 4", report.countNew, report.countOld, report.countNeverSucceeded)
+	}
+	if got := report.countByProblem; got[addrBookProblemOwnId] != 1 || got[addrBookProblemPrivate] != 1 || got[addrBookProblemBogus] != 1 {
+		t.Errorf("count by problem = %v", got)
+	}
+	var badIndexes []int
+	for _, bad := range report.badEntries {
+		badIndexes = append(badIndexes, bad.index)
+	}
+	if fmt.Sprint(badIndexes) != "[1 3 4]" {
+		t.Errorf("bad entries at %v, want [1 3 4]", badIndexes)
+	}
+
+	// own node ID is unknown
+	report = inspectAddrBook(addrBook, "", true)
+	if len(report.badEntries) != 1 || report.badEntries[0].problem != addrBookProblemBogus {
+		t.Errorf("want only the bogus entry, got %+v", report.badEntries)
+	}
+}
+
+func TestPruneAddrBook(t *testing.T) {
+	addrBook, err := unmarshalAddrBook([]byte(testAddrBook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := inspectAddrBook(addrBook, testAddrBookNodeId, false)
+
+	prunedBz, err := pruneAddrBook([]byte(testAddrBook), report.badEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prunedAddrBook, err := unmarshalAddrBook(prunedBz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prunedAddrBook.Key != addrBook.Key {
+		t.Errorf("key = %s, want %s", prunedAddrBook.Key, addrBook.Key)
+	}
+	var ids []string
+	for _, entry := range prunedAddrBook.Addrs {
+		ids = append(ids, entry.Addr.Id[:4])
+	}
+	if strings.Join(ids, ",") != "1111,3333,5555" {
+		t.Errorf("kept entries %v, want 1111, 3333, 5555", ids)
+	}
+	if pruned := inspectAddrBook(prunedAddrBook, testAddrBookNodeId, false); len(pruned.badEntries) != 0 {
+		t.Errorf("pruned address book still has bad entries: %+v", pruned.badEntries)
+	}
+
+	// entries are kept as is, including fields unknown to types.AddrBook
+	var raw struct {
+		Addrs []map[string]json.RawMessage `json:"addrs"`
+	}
+	if err := json.Unmarshal(prunedBz, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(raw.Addrs[1]["last_ban_time"]); got != `"2024-02-01T00:00:00Z"` {
+		t.Errorf("last_ban_time of the kept entry = %s", got)
+	}
+}
@@ -0,0 +1,469 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/sergeymakinen/go-systemdconf/v2"
+	"github.com/sergeymakinen/go-systemdconf/v2/unit"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	flagBaselineFile = "baseline-file"
+
+	baselineVersion         = 1
+	defaultBaselineFileName = "nodesc_baseline.json"
+)
+
+// runtimeChangingFiles are files which content changes while the node is running, only permission is tracked.
+var runtimeChangingFiles = map[string]bool{
+	"config/addrbook.json":           true,
+	"data/priv_validator_state.json": true,
+}
+
+func GetBaselineCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "baseline",
+		Short: "Freeze known-good state of a node home and detect drift",
+	}
+
+	cmd.AddCommand(getBaselineSaveCmd(), getBaselineDiffCmd())
+
+	return cmd
+}
+
+func getBaselineSaveCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "save [home]",
+		Short: "Record hashes of config files, settings, permissions and service unit of a node home",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+			serviceFilePath, _ := cmd.Flags().GetString(flagServiceFile)
+			etcRoot, _ := cmd.Flags().GetString(flagEtcRoot)
+			baselineFilePath := getBaselineFilePath(cmd)
+
+			baseline, err := takeBaseline(home, serviceFilePath, etcRoot, baselineFilePath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to take baseline of %s: %v\n", home, err)
+				return
+			}
+
+			bz, err := json.MarshalIndent(baseline, "", "  ")
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to marshal baseline: %v\n", err)
+				return
+			}
+
+			err = writeFileAtomically(baselineFilePath, bz, 0o600)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to write baseline file %s: %v\n", baselineFilePath, err)
+				return
+			}
+
+			fmt.Printf("Baseline saved to %s: %d files, %d settings\n", baselineFilePath, len(baseline.Files), len(baseline.Settings))
+			if baseline.Service == nil {
+				fmt.Printf("NOTICE: service unit is not recorded, provide --%s to include it\n", flagServiceFile)
+			}
+		},
+	}
+
+	cmd.Flags().String(flagServiceFile, "", "path to the service file to include in the baseline")
+	cmd.Flags().String(flagBaselineFile, defaultBaselineFileName, "path to the baseline file")
+	cmd.Flags().String(flagEtcRoot, "/etc", "where system config files are, used to check if the service is enabled")
+
+	return cmd
+}
+
+func getBaselineDiffCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "diff [home]",
+		Short: "Report every change of a node home since the baseline was saved",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+			etcRoot, _ := cmd.Flags().GetString(flagEtcRoot)
+			baselineFilePath := getBaselineFilePath(cmd)
+
+			bz, err := os.ReadFile(baselineFilePath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to read baseline file %s: %v\n", baselineFilePath, err)
+				return
+			}
+
+			var saved types.Baseline
+			err = json.Unmarshal(bz, &saved)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to unmarshal baseline file %s: %v\n", baselineFilePath, err)
+				return
+			}
+			if saved.Version != baselineVersion {
+				exitWithErrorMsgf("ERR: unsupported baseline version %d, re-create the baseline\n", saved.Version)
+				return
+			}
+
+			serviceFilePath, _ := cmd.Flags().GetString(flagServiceFile)
+			if serviceFilePath == "" && saved.Service != nil {
+				serviceFilePath = saved.Service.Path
+			}
+
+			current, err := takeBaseline(home, serviceFilePath, etcRoot, baselineFilePath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to inspect %s: %v\n", home, err)
+				return
+			}
+
+			changes := diffBaseline(saved, *current)
+			if len(changes) == 0 {
+				fmt.Printf("No drift detected since baseline saved at %s\n", saved.CreatedAt.Local().Format(time.RFC3339))
+				return
+			}
+
+			fmt.Printf("Drift detected since baseline saved at %s:\n", saved.CreatedAt.Local().Format(time.RFC3339))
+			for _, change := range changes {
+				fmt.Println("-", change)
+			}
+			os.Exit(1)
+		},
+	}
+
+	cmd.Flags().String(flagServiceFile, "", "path to the service file, default is the one recorded in the baseline")
+	cmd.Flags().String(flagBaselineFile, defaultBaselineFileName, "path to the baseline file")
+	cmd.Flags().String(flagEtcRoot, "/etc", "where system config files are, used to check if the service is enabled")
+
+	return cmd
+}
+
+// getBaselineFilePath returns the baseline file, default is in the current directory rather than the home,
+// so the baseline is not within the tree it describes.
+func getBaselineFilePath(cmd *cobra.Command) string {
+	baselineFilePath, _ := cmd.Flags().GetString(flagBaselineFile)
+	if baselineFilePath == "" {
+		baselineFilePath = defaultBaselineFileName
+	}
+	return baselineFilePath
+}
+
+func takeBaseline(home, serviceFilePath, etcRoot, baselineFilePath string) (*types.Baseline, error) {
+	_, exists, isDir, err := utils.FileInfo(home)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || !isDir {
+		return nil, fmt.Errorf("home directory does not exist: %s", home)
+	}
+
+	absHome, err := filepath.Abs(home)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := &types.Baseline{
+		Version:   baselineVersion,
+		Home:      absHome,
+		CreatedAt: time.Now().UTC(),
+		Files:     make(map[string]types.BaselineFile),
+		Settings:  make(map[string]string),
+	}
+
+	absBaselineFilePath, _ := filepath.Abs(baselineFilePath)
+
+	addFile := func(relPath string, withHash bool) error {
+		filePath := filepath.Join(home, relPath)
+		if abs, _ := filepath.Abs(filePath); abs == absBaselineFilePath {
+			return nil
+		}
+
+		perm, exists, isDir, err := utils.FileInfo(filePath)
+		if err != nil || !exists {
+			return err
+		}
+
+		file := types.BaselineFile{
+			Perm:  formatPerm(perm),
+			IsDir: isDir,
+		}
+		if !isDir && withHash && !runtimeChangingFiles[filepath.ToSlash(relPath)] {
+			file.Sha256, err = sha256File(filePath)
+			if err != nil {
+				return err
+			}
+		}
+
+		baseline.Files[filepath.ToSlash(relPath)] = file
+		return nil
+	}
+
+	walk := func(relDir string) error {
+		root := filepath.Join(home, relDir)
+		return filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(home, filePath)
+			if err != nil {
+				return err
+			}
+			return addFile(relPath, true)
+		})
+	}
+
+	if err := addFile(".", false); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "config" || strings.HasPrefix(name, "keyring-") {
+			if err := walk(name); err != nil {
+				return nil, errors.Wrapf(err, "failed to walk %s", name)
+			}
+		}
+	}
+
+	// data directory content is huge and keeps changing, only track the directory and the signing state
+	if err := addFile("data", false); err != nil {
+		return nil, err
+	}
+	if err := addFile(filepath.Join("data", "priv_validator_state.json"), false); err != nil {
+		return nil, err
+	}
+
+	configPath := path.Join(home, "config")
+	if err := addBaselineTomlSettings(baseline.Settings, path.Join(configPath, "app.toml"), &types.AppToml{}); err != nil {
+		return nil, err
+	}
+	if err := addBaselineTomlSettings(baseline.Settings, path.Join(configPath, "config.toml"), &types.ConfigToml{}); err != nil {
+		return nil, err
+	}
+	if err := addBaselineTomlSettings(baseline.Settings, path.Join(configPath, "client.toml"), nil); err != nil {
+		return nil, err
+	}
+
+	if serviceFilePath != "" {
+		baseline.Service, err = takeBaselineService(serviceFilePath, etcRoot)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to inspect service file %s", serviceFilePath)
+		}
+	}
+
+	return baseline, nil
+}
+
+// addBaselineTomlSettings flattens settings of the TOML file into the map.
+// If typed is provided, only the settings inspected by the check are recorded.
+func addBaselineTomlSettings(settings map[string]string, filePath string, typed any) error {
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if typed != nil {
+		if err := toml.Unmarshal(bz, typed); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal %s", filePath)
+		}
+		bz, err = toml.Marshal(typed)
+		if err != nil {
+			return err
+		}
+	}
+
+	var raw map[string]any
+	if err := toml.Unmarshal(bz, &raw); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", filePath)
+	}
+
+	_, fileName := path.Split(filePath)
+	flattenSettings(raw, "", func(key string, value string) {
+		settings[fileName+":"+key] = value
+	})
+	return nil
+}
+
+func flattenSettings(m map[string]any, prefix string, put func(key, value string)) {
+	for key, value := range m {
+		dottedKey := key
+		if prefix != "" {
+			dottedKey = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flattenSettings(nested, dottedKey, put)
+			continue
+		}
+		put(dottedKey, fmt.Sprint(value))
+	}
+}
+
+func takeBaselineService(serviceFilePath, etcRoot string) (*types.BaselineService, error) {
+	perm, exists, isDir, err := utils.FileInfo(serviceFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || isDir {
+		return nil, fmt.Errorf("service file does not exist")
+	}
+
+	bz, err := os.ReadFile(serviceFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var sf unit.ServiceFile
+	if err := systemdconf.Unmarshal(bz, &sf); err != nil {
+		return nil, err
+	}
+
+	absServiceFilePath, err := filepath.Abs(serviceFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	_, serviceFileName := filepath.Split(serviceFilePath)
+	enabled, err := isSystemdUnitEnabled(filepath.Join(etcRoot, "systemd", "system"), serviceFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.BaselineService{
+		Path:    absServiceFilePath,
+		Perm:    formatPerm(perm),
+		Sha256:  sha256Hex(bz),
+		Enabled: enabled,
+		Settings: map[string]string{
+			"Unit.Description":   sf.Unit.Description.String(),
+			"Unit.After":         sf.Unit.After.String(),
+			"Service.User":       sf.Service.User.String(),
+			"Service.ExecStart":  sf.Service.ExecStart.String(),
+			"Service.Restart":    sf.Service.Restart.String(),
+			"Service.RestartSec": sf.Service.RestartSec.String(),
+			"Install.WantedBy":   sf.Install.WantedBy.String(),
+		},
+	}, nil
+}
+
+// diffBaseline returns human-readable changes from saved to current, sorted for stable output.
+func diffBaseline(saved, current types.Baseline) []string {
+	var changes []string
+
+	for _, relPath := range sortedKeys(saved.Files) {
+		savedFile := saved.Files[relPath]
+		currentFile, found := current.Files[relPath]
+		if !found {
+			changes = append(changes, fmt.Sprintf("removed %s", relPath))
+			continue
+		}
+		changes = append(changes, diffPerm(relPath, savedFile.Perm, currentFile.Perm)...)
+		if savedFile.IsDir != currentFile.IsDir {
+			changes = append(changes, fmt.Sprintf("type changed %s: directory=%t => directory=%t", relPath, savedFile.IsDir, currentFile.IsDir))
+		} else if savedFile.Sha256 != currentFile.Sha256 {
+			changes = append(changes, fmt.Sprintf("content changed %s", relPath))
+		}
+	}
+	for _, relPath := range sortedKeys(current.Files) {
+		if _, found := saved.Files[relPath]; !found {
+			changes = append(changes, fmt.Sprintf("new %s (permission %s)", relPath, current.Files[relPath].Perm))
+		}
+	}
+
+	changes = append(changes, diffSettings("setting", saved.Settings, current.Settings)...)
+
+	switch {
+	case saved.Service == nil && current.Service != nil:
+		changes = append(changes, fmt.Sprintf("service file %s was not recorded in the baseline", current.Service.Path))
+	case saved.Service != nil && current.Service == nil:
+		changes = append(changes, fmt.Sprintf("service file %s is missing", saved.Service.Path))
+	case saved.Service != nil && current.Service != nil:
+		changes = append(changes, diffPerm(current.Service.Path, saved.Service.Perm, current.Service.Perm)...)
+		if saved.Service.Enabled != current.Service.Enabled {
+			changes = append(changes, fmt.Sprintf("service enabled on boot changed: %t => %t", saved.Service.Enabled, current.Service.Enabled))
+		}
+		changes = append(changes, diffSettings("service setting", saved.Service.Settings, current.Service.Settings)...)
+		if saved.Service.Sha256 != current.Service.Sha256 {
+			changes = append(changes, fmt.Sprintf("content changed %s", current.Service.Path))
+		}
+	}
+
+	return changes
+}
+
+func diffPerm(name, savedPerm, currentPerm string) []string {
+	if savedPerm == currentPerm {
+		return nil
+	}
+
+	saved, errSaved := strconv.ParseUint(savedPerm, 8, 32)
+	current, errCurrent := strconv.ParseUint(currentPerm, 8, 32)
+	if errSaved == nil && errCurrent == nil && current&^saved != 0 {
+		return []string{fmt.Sprintf("permission loosened %s: %s => %s", name, savedPerm, currentPerm)}
+	}
+	return []string{fmt.Sprintf("permission changed %s: %s => %s", name, savedPerm, currentPerm)}
+}
+
+func diffSettings(kind string, saved, current map[string]string) []string {
+	var changes []string
+	for _, key := range sortedKeys(saved) {
+		currentValue, found := current[key]
+		if !found {
+			changes = append(changes, fmt.Sprintf("%s removed %s (was %q)", kind, key, saved[key]))
+		} else if currentValue != saved[key] {
+			changes = append(changes, fmt.Sprintf("%s changed %s: %q => %q", kind, key, saved[key], currentValue))
+		}
+	}
+	for _, key := range sortedKeys(current) {
+		if _, found := saved[key]; !found {
+			changes = append(changes, fmt.Sprintf("%s added %s = %q", kind, key, current[key]))
+		}
+	}
+	return changes
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatPerm(perm os.FileMode) string {
+	return fmt.Sprintf("%#o", perm.Perm())
+}
+
+func sha256File(filePath string) (string, error) {
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(bz), nil
+}
+
+func sha256Hex(bz []byte) string {
+	hash := sha256.Sum256(bz)
+	return hex.EncodeToString(hash[:])
+}
+
+func init() {
+	rootCmd.AddCommand(GetBaselineCmd())
+}
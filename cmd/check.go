@@ -1,39 +1,101 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/bcdevtools/node-setup-check/constants"
 	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
 	"github.com/spf13/cobra"
-	"net/http"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
-	"sync"
 	"time"
 )
 
 const (
-	flagType        = "type"
-	flagServiceFile = "service-file"
+	flagType            = "type"
+	flagServiceFile     = "service-file"
+	flagOffline         = "offline"
+	flagReleaseEndpoint = "release-endpoint"
+	flagReleaseTimeout  = "release-timeout"
+	flagReleaseCacheTtl = "release-cache-ttl"
+	flagOutput          = "output"
+	flagPromTextfile    = "prometheus-textfile"
+	flagHost            = "host"
+	flagProcRoot        = "proc-root"
+	flagEtcRoot         = "etc-root"
+	flagTimeSyncStatus  = "time-sync-status"
+	flagHostHardening   = "host-hardening"
+	flagRemoteSigner    = "remote-signer"
+	flagSignerConfig    = "signer-config"
+	flagPrivateNetwork  = "private-network"
+	flagChainRegistry   = "chain-registry"
 )
 
-var waitGroup sync.WaitGroup
-
 func GetCheckCmd() *cobra.Command {
 	validTargetValues := strings.Join(types.AllNodeTypeNames(), "/")
 
 	var cmd = &cobra.Command{
-		Use:     "check [home]",
+		Use:     "check [home] [more homes...]",
 		Aliases: []string{},
-		Args:    cobra.ExactArgs(1),
+		Args:    cobra.MinimumNArgs(1),
 		Short:   "Check node setup",
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("App version", constants.VERSION)
-			fmt.Println("NOTICE: always update to latest version for accurate check")
-			go checkLatestRelease()
-			time.Sleep(2 * time.Second)
+			outputFormat, _ := cmd.Flags().GetString(flagOutput)
+			if !isValidOutputFormat(outputFormat) {
+				exitWithErrorMsgf("ERR: Invalid output format, can be either %s\n", strings.Join(allOutputFormats, "/"))
+				return
+			}
+
+			// when rendering machine-readable report to stdout, human-readable messages go to stderr
+			var out io.Writer = os.Stdout
+			if outputFormat != outputText {
+				out = os.Stderr
+			}
+
+			writeReport := func(records []checkRecord) {
+				switch outputFormat {
+				case outputSarif:
+					if err := writeSarifReport(os.Stdout, records); err != nil {
+						exitWithErrorMsgf("ERR: failed to write SARIF report: %v\n", err)
+						return
+					}
+				case outputJunit:
+					if err := writeJunitReport(os.Stdout, fmt.Sprintf("%s check %s", constants.BINARY_NAME, strings.Join(args, " ")), evaluatedRules, records); err != nil {
+						exitWithErrorMsgf("ERR: failed to write JUnit report: %v\n", err)
+						return
+					}
+				}
+			}
+			// CI expects the report even when the check is aborted, the cause is reported as a fatal record
+			exitHooks = append(exitHooks, func(exitError string) {
+				writeReport(append(checkRecords, checkRecord{
+					fatal:   true,
+					target:  ruleTarget("nodesc", "aborted"),
+					message: strings.TrimPrefix(exitError, "ERR: "),
+				}))
+			})
+
+			fmt.Fprintln(out, "App version", constants.VERSION)
+			fmt.Fprintln(out, "NOTICE: always update to latest version for accurate check")
+
+			offline, _ := cmd.Flags().GetBool(flagOffline)
+			releaseEndpoint, _ := cmd.Flags().GetString(flagReleaseEndpoint)
+			releaseTimeout, _ := cmd.Flags().GetDuration(flagReleaseTimeout)
+			releaseCacheTtl, _ := cmd.Flags().GetDuration(flagReleaseCacheTtl)
+			if !offline && releaseTimeout <= 0 {
+				exitWithErrorMsgf("ERR: --%s must be positive\n", flagReleaseTimeout)
+				return
+			}
+			latestReleaseCheck := startLatestReleaseCheck(latestReleaseCheckOptions{
+				offline:   offline,
+				endpoint:  releaseEndpoint,
+				timeout:   releaseTimeout,
+				cacheFile: defaultLatestReleaseCacheFile(),
+				cacheTtl:  releaseCacheTtl,
+			})
 
 			typeName, _ := cmd.Flags().GetString(flagType)
 			nodeType := types.NodeTypeFromString(typeName)
@@ -42,8 +104,17 @@ func GetCheckCmd() *cobra.Command {
 				return
 			}
 
+			homes := args
+			home := homes[0]
+			isMultiHome := len(homes) > 1
+
 			isLinux := runtime.GOOS == "linux"
 			requireServiceFileForValidatorOnLinux := nodeType == types.ValidatorNode && isLinux
+			if requireServiceFileForValidatorOnLinux && isMultiHome {
+				// service file belongs to a single home
+				requireServiceFileForValidatorOnLinux = false
+				printfStdErr("WARN: service file, auto restart and ownership checks are skipped when checking multiple homes, check each validator home with --%s\n", flagServiceFile)
+			}
 
 			serviceFilePath, _ := cmd.Flags().GetString(flagServiceFile)
 			if requireServiceFileForValidatorOnLinux && serviceFilePath == "" {
@@ -54,35 +125,148 @@ func GetCheckCmd() *cobra.Command {
 				return
 			}
 
+			remoteSigner, _ := cmd.Flags().GetBool(flagRemoteSigner)
+			signerConfigFilePath, _ := cmd.Flags().GetString(flagSignerConfig)
+			if remoteSigner && nodeType != types.ValidatorNode {
+				exitWithErrorMsgf("ERR: remove flag \"--%s\", only be used for validator\n", flagRemoteSigner)
+				return
+			}
+			if signerConfigFilePath != "" && !remoteSigner {
+				exitWithErrorMsgf("ERR: --%s requires --%s\n", flagSignerConfig, flagRemoteSigner)
+				return
+			}
+
+			bech32Prefix, _ := cmd.Flags().GetString(flagBech32Prefix)
+			privateNetwork, _ := cmd.Flags().GetBool(flagPrivateNetwork)
+			chainRegistryPath, _ := cmd.Flags().GetString(flagChainRegistry)
+
+			checkHostMachine, _ := cmd.Flags().GetBool(flagHost)
+			procRoot, _ := cmd.Flags().GetString(flagProcRoot)
+			etcRoot, _ := cmd.Flags().GetString(flagEtcRoot)
+			timeSyncStatusFilePath, _ := cmd.Flags().GetString(flagTimeSyncStatus)
+			if checkHostMachine && !isLinux && procRoot == utils.DefaultProcRoot {
+				exitWithErrorMsgf("ERR: --%s requires procfs, only available on Linux, or provide --%s\n", flagHost, flagProcRoot)
+				return
+			}
+			checkHostMachineHardening, _ := cmd.Flags().GetBool(flagHostHardening)
+			if !checkHostMachine && timeSyncStatusFilePath != "" {
+				exitWithErrorMsgf("ERR: --%s requires --%s\n", flagTimeSyncStatus, flagHost)
+				return
+			}
+
+			promTextfile, _ := cmd.Flags().GetString(flagPromTextfile)
+			if isMultiHome {
+				// these belong to a single node
+				for _, flagName := range []string{flagServiceFile, flagSignerConfig, flagPromTextfile, flagHost} {
+					if cmd.Flags().Changed(flagName) {
+						exitWithErrorMsgf("ERR: --%s can only be used when checking a single home\n", flagName)
+						return
+					}
+				}
+			}
+			writePromTextfile := func(success bool) {
+				if promTextfile == "" {
+					return
+				}
+				homeLabel, err := filepath.Abs(home)
+				if err != nil {
+					homeLabel = home
+				}
+				content := renderPrometheusTextfile(homeLabel, nodeType, checkRecords, success, time.Now())
+				if err := writeFileAtomically(promTextfile, []byte(content), 0o644); err != nil {
+					printfStdErr("ERR: failed to write Prometheus textfile %s: %v\n", promTextfile, err)
+				}
+			}
+			exitHooks = append(exitHooks, func(string) {
+				writePromTextfile(false)
+			})
+
 			defer func() {
-				waitGroup.Wait()
+				if latestReleaseCheck != nil {
+					awaitLatestReleaseCheck(latestReleaseCheck, releaseTimeout)
+				}
+
+				exitHooks = nil
+				writePromTextfile(len(checkRecords) == 0)
+
+				writeReport(checkRecords)
+
 				if len(checkRecords) == 0 {
-					fmt.Println("All checks passed")
+					fmt.Fprintln(out, "All checks passed")
 					return
 				}
 
-				printCheckRecords()
+				if outputFormat == outputText {
+					printCheckRecords()
+				}
 				os.Exit(1)
 			}()
 
-			home := args[0]
-			checkHome(home)
+			var nodeIds []string
+			for _, home := range homes {
+				if isMultiHome {
+					fmt.Fprintln(out, "Checking", home)
+				}
+
+				checkHome(home)
+				checkHomeSymlinks(home)
 
-			checkHomeKeyring(home, nodeType == types.ValidatorNode)
-			checkHomeConfig(home, nodeType)
-			checkHomeData(home, nodeType)
+				checkHomeKeyring(home, nodeType == types.ValidatorNode)
+				configToml, appToml, genesis, nodeId := checkHomeConfig(home, nodeType, remoteSigner, privateNetwork)
+				if nodeId != "" {
+					fmt.Fprintln(out, "Node ID:", nodeId)
+				}
+				nodeIds = append(nodeIds, nodeId)
+				if chainRegistryPath != "" {
+					if chain, chainFilePath := checkChainRegistry(home, nodeType, chainRegistryPath, configToml, appToml); chain != nil {
+						fmt.Fprintf(out, "Chain registry: %s (%s)\n", chain.ChainName, chainFilePath)
+					}
+				}
+				if signerConfigFilePath != "" {
+					checkRemoteSignerConfig(home, configToml, signerConfigFilePath)
+				}
+				if !remoteSigner {
+					if key := checkConsensusKey(home, nodeType, bech32Prefix, genesis); key != nil {
+						if key.valcons != "" {
+							fmt.Fprintf(out, "Consensus address: %X (%s)\n", key.address, key.valcons)
+						} else {
+							fmt.Fprintf(out, "Consensus address: %X, provide --%s to show the valcons address\n", key.address, flagBech32Prefix)
+						}
+						if len(key.genesisMatches) == 0 {
+							fmt.Fprintln(out, "Consensus key is not in the genesis validator set or gentxs")
+						}
+						for _, match := range key.genesisMatches {
+							fmt.Fprintf(out, "Consensus key is in %s of genesis: %s\n", match.source, match.String())
+						}
+					}
+				}
+				checkHomeData(home, nodeType, remoteSigner)
+				checkHomeDataDbBackend(home, nodeType, configToml, appToml)
+			}
+			if isMultiHome {
+				checkDuplicateNodeIds(homes, nodeIds)
+			}
 			if requireServiceFileForValidatorOnLinux {
 				checkServiceFileForValidatorOnLinux(home, serviceFilePath)
+				checkAutoRestart(etcRoot, serviceFilePath)
+				checkHomeOwnership(home, etcRoot, serviceFilePath)
+			}
+			if checkHostMachine {
+				checkHost(home, nodeType, procRoot, serviceFilePath)
+				checkTimeSync(nodeType, etcRoot, timeSyncStatusFilePath)
+			}
+			if checkHostMachineHardening {
+				checkHostHardening(nodeType, etcRoot)
 			}
 
-			fmt.Println("NOTICE: some tasks need to be checked manually:")
+			fmt.Fprintln(out, "NOTICE: some tasks need to be checked manually:")
 
 			var countNotice int
 			printNotice := func(message, suggest string) {
 				countNotice++
-				fmt.Printf("%d. %s\n", countNotice, message)
+				fmt.Fprintf(out, "%d. %s\n", countNotice, message)
 				if suggest != "" {
-					fmt.Println("> " + suggest)
+					fmt.Fprintln(out, "> "+suggest)
 				}
 			}
 			printNotice("Ensure P2P port is open on firewall", "sudo ufw status")
@@ -95,53 +279,36 @@ func GetCheckCmd() *cobra.Command {
 				printNotice("Ensure RPC port is open on firewall", "sudo ufw status")
 				printNotice("Ensure Rest-API, Json-RPC ports are not allowed from outside", "sudo ufw status")
 			}
+			if remoteSigner {
+				printNotice("Ensure signing state of the remote signer is persisted and migrated with it, priv_validator_state.json of the node is not used", "check state_file of tmkms, or the state directory of horcrux")
+			}
 			printNotice("Check config.toml for 'fast_sync' and 'block_sync', if exists, set to true", "")
-			fmt.Println("WARN: after checked and fixed all issues, re-check again using this tool before running node, otherwise you probably miss something")
+			fmt.Fprintln(out, "WARN: after checked and fixed all issues, re-check again using this tool before running node, otherwise you probably miss something")
 		},
 	}
 
 	cmd.Flags().String(flagType, "", fmt.Sprintf("type of node to check, can be: %s", validTargetValues))
 	cmd.Flags().String(flagServiceFile, "", "path to the service file to check, required for validator node on Linux")
+	cmd.Flags().Bool(flagOffline, false, "do not check for the latest release, for air-gapped machines")
+	cmd.Flags().String(flagReleaseEndpoint, defaultReleaseEndpoint, "endpoint to fetch the latest release from, GitHub API compatible")
+	cmd.Flags().Duration(flagReleaseTimeout, 5*time.Second, "timeout of checking the latest release")
+	cmd.Flags().String(flagOutput, outputText, fmt.Sprintf("format of the report, can be: %s", strings.Join(allOutputFormats, "/")))
+	cmd.Flags().String(flagPromTextfile, "", "write result as Prometheus metrics to this file, for the textfile collector of node_exporter")
+	cmd.Flags().Duration(flagReleaseCacheTtl, 6*time.Hour, "how long the latest release is cached on disk, 0 to disable cache")
+	cmd.Flags().Bool(flagHost, false, "also check the machine: CPU, RAM, open files limit, swap, mount of the home and time sync, must run on the node machine")
+	cmd.Flags().String(flagProcRoot, utils.DefaultProcRoot, "where procfs is mounted, used by --"+flagHost)
+	cmd.Flags().String(flagEtcRoot, "/etc", fmt.Sprintf("where system config files are, used by --%s, --%s, auto restart detection and ownership check of validator", flagHost, flagHostHardening))
+	cmd.Flags().Bool(flagRemoteSigner, false, "validator signs using remote signer like tmkms or horcrux via priv_validator_laddr, only placeholder key is allowed on disk")
+	cmd.Flags().String(flagSignerConfig, "", "path to tmkms.toml or horcrux config.yaml to validate against the node, used by --"+flagRemoteSigner)
+	cmd.Flags().Bool(flagHostHardening, false, "also audit SSH settings of the machine, must run on the node machine")
+	cmd.Flags().Bool(flagPrivateNetwork, false, "nodes are connected via private network, allow loopback, private and link-local addresses in seeds and persistent_peers")
+	cmd.Flags().String(flagChainRegistry, "", "path to a local checkout of the cosmos chain-registry or a chain.json file, to compare seeds, persistent_peers and minimum-gas-prices, genesis hash is compared when codebase.genesis.sha256 is added locally")
+	cmd.Flags().String(flagBech32Prefix, "", "bech32 prefix of the chain, e.g. cosmos, to show the valcons address, default to prefix of genesis accounts")
+	cmd.Flags().String(flagTimeSyncStatus, "", "file contains output of \"timedatectl show\", \"timedatectl timesync-status\", \"chronyc tracking\" or chrony tracking.log, to check clock offset, used by --"+flagHost)
 
 	return cmd
 }
 
-func checkLatestRelease() {
-	waitGroup.Add(1)
-	defer func() {
-		r := recover()
-		if r != nil {
-			printfStdErr("ERR: failed to check latest release: %v\n", r)
-		}
-		waitGroup.Done()
-	}()
-
-	resp, err := http.Get("https://api.github.com/repos/bcdevtools/node-setup-check/releases/latest")
-	if err != nil {
-		panic(err)
-	}
-
-	defer resp.Body.Close()
-
-	var release struct {
-		TagName string `json:"tag_name"`
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&release)
-	if err != nil {
-		panic(err)
-	}
-
-	latestTagName := strings.TrimPrefix(release.TagName, "v")
-	currentVersion := strings.TrimPrefix(constants.VERSION, "v")
-	if latestTagName != currentVersion {
-		warnRecord(
-			fmt.Sprintf("latest release is v%s, must use latest version to prevent bugs and new logics", latestTagName),
-			fmt.Sprintf("cd ~ && go install github.com/bcdevtools/node-setup-check/cmd/nodesc@%s", release.TagName),
-		)
-	}
-}
-
 func init() {
 	rootCmd.AddCommand(GetCheckCmd())
 }
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/sergeymakinen/go-systemdconf/v2"
+	"github.com/sergeymakinen/go-systemdconf/v2/unit"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// restartVerbs are systemctl/service verbs which (re)start a unit.
+var restartVerbs = map[string]bool{
+	"start":             true,
+	"restart":           true,
+	"try-restart":       true,
+	"reload-or-restart": true,
+	"condrestart":       true,
+	"force-reload":      true,
+}
+
+var needrestartRegex = regexp.MustCompile(`^\s*\$nrconf\{restart}\s*=\s*['"](\w)['"]`)
+
+// checkAutoRestart detects mechanisms which reboot the machine or restart the validator unattended,
+// which breaks the purpose of Restart=no and disabled on boot.
+// Files are read under etcRoot, user crontabs are read relatively to the parent of etcRoot.
+func checkAutoRestart(etcRoot string, serviceFilePath string) {
+	bz, err := os.ReadFile(serviceFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read service file: %v\n", err)
+		return
+	}
+	var sf unit.ServiceFile
+	err = systemdconf.Unmarshal(bz, &sf)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to unmarshal service file: %v\n", err)
+		return
+	}
+
+	unitName := normalizeSystemdUnitName(serviceFilePath)
+	var binaryName string
+	if execStart := strings.Fields(sf.Service.ExecStart.String()); len(execStart) > 0 {
+		binaryName = filepath.Base(strings.TrimLeft(execStart[0], "@-:+!"))
+	}
+	isRestartingNode := func(line string) bool {
+		return isCommandRestartingNode(line, unitName, binaryName)
+	}
+
+	evaluatedRule(
+		ruleTarget("auto-restart", "cron"),
+		ruleTarget("auto-restart", "timer"),
+		ruleTarget("auto-restart", "systemd-timer"),
+		ruleTarget("auto-restart", "monitoring-agent"),
+	)
+
+	checkAutoRestartUnattendedUpgrades(etcRoot)
+	checkAutoRestartNeedrestart(etcRoot, unitName)
+
+	// cron
+	var cronFiles []string
+	for _, pattern := range []string{
+		filepath.Join(etcRoot, "crontab"),
+		filepath.Join(etcRoot, "cron.d", "*"),
+		filepath.Join(etcRoot, "cron.hourly", "*"),
+		filepath.Join(etcRoot, "cron.daily", "*"),
+		filepath.Join(etcRoot, "cron.weekly", "*"),
+		filepath.Join(etcRoot, "cron.monthly", "*"),
+		filepath.Join(filepath.Dir(etcRoot), "var", "spool", "cron", "crontabs", "*"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to find cron files: %v\n", err)
+			return
+		}
+		cronFiles = append(cronFiles, matches...)
+	}
+	for _, cronFile := range cronFiles {
+		forEachMatchingLine(cronFile, isRestartingNode, func(lineNo int, line string) {
+			fatalRecord(
+				lineTarget("auto-restart", cronFile, "cron", lineNo),
+				fmt.Sprintf("cron job starts/restarts the validator: %s", strings.TrimSpace(line)),
+				fmt.Sprintf("remove the job at %s:%d", cronFile, lineNo),
+			)
+		})
+	}
+
+	// systemd timers, the activated service is the one named by Unit= or the same name as the timer
+	systemDir := filepath.Join(etcRoot, "systemd", "system")
+	timerFiles, err := filepath.Glob(filepath.Join(systemDir, "*.timer"))
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to find systemd timers: %v\n", err)
+		return
+	}
+	for _, timerFile := range timerFiles {
+		bz, err := os.ReadFile(timerFile)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to read systemd timer %s: %v\n", timerFile, err)
+			return
+		}
+		var tf unit.TimerFile
+		if err := systemdconf.Unmarshal(bz, &tf); err != nil {
+			warnRecord(fileTarget("auto-restart", timerFile, "timer"), fmt.Sprintf("failed to parse systemd timer %s: %v", timerFile, err), "")
+			continue
+		}
+
+		activatedUnit := strings.TrimSuffix(filepath.Base(timerFile), ".timer") + ".service"
+		if tf.Timer.Unit.String() != "" {
+			activatedUnit = normalizeSystemdUnitName(tf.Timer.Unit.String())
+		}
+		timerName := filepath.Base(timerFile)
+		activatedUnitFile := filepath.Join(systemDir, activatedUnit)
+		if _, exists, _, _ := utils.FileInfo(activatedUnitFile); !exists {
+			continue
+		}
+		forEachMatchingLine(activatedUnitFile, func(line string) bool {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			return ok && strings.HasPrefix(key, "ExecStart") && isRestartingNode(value)
+		}, func(lineNo int, line string) {
+			fatalRecord(
+				lineTarget("auto-restart", activatedUnitFile, "systemd-timer", lineNo),
+				fmt.Sprintf("systemd timer %s starts/restarts the validator: %s", timerName, strings.TrimSpace(line)),
+				fmt.Sprintf("sudo systemctl disable --now %s, then remove %s and %s", timerName, timerFile, activatedUnitFile),
+			)
+		})
+	}
+
+	// monitoring agents
+	var agentFiles []string
+	for _, pattern := range []string{
+		filepath.Join(etcRoot, "monit", "monitrc"),
+		filepath.Join(etcRoot, "monitrc"),
+		filepath.Join(etcRoot, "monit", "conf.d", "*"),
+		filepath.Join(etcRoot, "monit", "conf-enabled", "*"),
+		filepath.Join(etcRoot, "supervisor", "supervisord.conf"),
+		filepath.Join(etcRoot, "supervisor", "conf.d", "*"),
+		filepath.Join(etcRoot, "supervisord.conf"),
+		filepath.Join(etcRoot, "supervisord.d", "*"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to find monitoring agent config files: %v\n", err)
+			return
+		}
+		agentFiles = append(agentFiles, matches...)
+	}
+	for _, agentFile := range agentFiles {
+		forEachMatchingLine(agentFile, func(line string) bool {
+			// monit: start program = "/bin/systemctl start node"
+			// supervisor: command=/usr/bin/noded start
+			_, value, ok := strings.Cut(line, "=")
+			return ok && isRestartingNode(strings.Trim(strings.TrimSpace(value), `"`))
+		}, func(lineNo int, line string) {
+			fatalRecord(
+				lineTarget("auto-restart", agentFile, "monitoring-agent", lineNo),
+				fmt.Sprintf("monitoring agent is configured to start/restart the validator: %s", strings.TrimSpace(line)),
+				"monitoring agent should only alert, remove the start/restart action then reload the agent",
+			)
+		})
+	}
+}
+
+// checkAutoRestartUnattendedUpgrades detects unattended-upgrades which reboots the machine after upgrading.
+// apt reads apt.conf.d in lexical order, the last value wins, settings can be flat or nested in blocks.
+func checkAutoRestartUnattendedUpgrades(etcRoot string) {
+	aptConfFiles, err := filepath.Glob(filepath.Join(etcRoot, "apt", "apt.conf.d", "*"))
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to find apt config files: %v\n", err)
+		return
+	}
+	sort.Strings(aptConfFiles)
+	evaluatedRule(ruleTarget("auto-restart", "unattended-upgrades"))
+
+	var periodicEnabled bool
+	var autoReboot bool
+	var autoRebootFile string
+	var autoRebootLine int
+	for _, aptConfFile := range aptConfFiles {
+		settings, err := utils.ReadAptConf(aptConfFile)
+		if err != nil {
+			warnRecord(
+				fileTarget("auto-restart", aptConfFile, "unattended-upgrades"),
+				fmt.Sprintf("unable to parse apt config file %s: %v, Automatic-Reboot of unattended-upgrades was not checked in it", aptConfFile, err),
+				"verify with: apt-config dump | grep -i Automatic-Reboot",
+			)
+			continue
+		}
+		for _, setting := range settings {
+			switch {
+			case strings.EqualFold(setting.Key, "APT::Periodic::Unattended-Upgrade"):
+				periodicEnabled = setting.Value != "0" && setting.Value != ""
+			case strings.EqualFold(setting.Key, "Unattended-Upgrade::Automatic-Reboot"):
+				autoReboot = isAptConfTrue(setting.Value)
+				autoRebootFile, autoRebootLine = aptConfFile, setting.Line
+			}
+		}
+	}
+
+	if periodicEnabled && autoReboot {
+		fatalRecord(
+			lineTarget("auto-restart", autoRebootFile, "unattended-upgrades", autoRebootLine),
+			"unattended-upgrades is enabled with Automatic-Reboot, the machine will be rebooted unattended",
+			fmt.Sprintf("set Unattended-Upgrade::Automatic-Reboot \"false\"; at %s:%d", autoRebootFile, autoRebootLine),
+		)
+	}
+}
+
+// isAptConfTrue returns true for the values apt accepts as true.
+func isAptConfTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1", "enable", "with":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkAutoRestartNeedrestart detects needrestart in automatic mode, which restarts services after upgrading libraries,
+// unless the unit is excluded by override_rc.
+func checkAutoRestartNeedrestart(etcRoot string, unitName string) {
+	var confFiles []string
+	for _, pattern := range []string{
+		filepath.Join(etcRoot, "needrestart", "needrestart.conf"),
+		filepath.Join(etcRoot, "needrestart", "conf.d", "*.conf"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to find needrestart config files: %v\n", err)
+			return
+		}
+		confFiles = append(confFiles, matches...)
+	}
+	evaluatedRule(ruleTarget("auto-restart", "needrestart"))
+
+	var autoMode bool
+	var autoModeFile string
+	var autoModeLine int
+	var excluded bool
+	// qr(^node) => 0, within $nrconf{override_rc} = { ... } or $nrconf{override_rc}{qr(^node)} = 0;
+	unitRegex := regexp.MustCompile(`qr\(\^?` + regexp.QuoteMeta(strings.TrimSuffix(unitName, ".service")) + `[^)]*\)}?\s*=>?\s*0`)
+	for _, confFile := range confFiles {
+		forEachMatchingLine(confFile, func(line string) bool {
+			return !strings.HasPrefix(strings.TrimSpace(line), "#")
+		}, func(lineNo int, line string) {
+			if matches := needrestartRegex.FindStringSubmatch(line); matches != nil {
+				autoMode = matches[1] == "a"
+				autoModeFile, autoModeLine = confFile, lineNo
+			}
+			if unitRegex.MatchString(line) {
+				excluded = true
+			}
+		})
+	}
+
+	if autoMode && !excluded {
+		fatalRecord(
+			lineTarget("auto-restart", autoModeFile, "needrestart", autoModeLine),
+			"needrestart is in automatic mode, the validator will be restarted after upgrading libraries",
+			fmt.Sprintf("set $nrconf{restart} = 'l'; at %s:%d, or exclude the unit by $nrconf{override_rc}{qr(^%s)} = 0;", autoModeFile, autoModeLine, regexp.QuoteMeta(strings.TrimSuffix(unitName, ".service"))),
+		)
+	}
+}
+
+// isCommandRestartingNode checks if the command line starts/restarts the unit, or starts the binary directly.
+func isCommandRestartingNode(line string, unitName string, binaryName string) bool {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return false
+	}
+
+	unitBase := strings.TrimSuffix(unitName, ".service")
+	tokens := strings.FieldsFunc(line, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ';' || r == '&' || r == '|' || r == '"' || r == '\''
+	})
+
+	var afterSystemctl, afterService, afterRestartVerb, afterServiceName bool
+	for i, token := range tokens {
+		base := filepath.Base(token)
+		switch {
+		case base == "systemctl":
+			afterSystemctl, afterService, afterRestartVerb, afterServiceName = true, false, false, false
+		case base == "service":
+			afterSystemctl, afterService, afterRestartVerb, afterServiceName = false, true, false, false
+		case afterSystemctl && restartVerbs[token]:
+			afterRestartVerb = true
+		case afterSystemctl && afterRestartVerb && (token == unitName || token == unitBase):
+			return true
+		case afterService && (token == unitName || token == unitBase):
+			afterServiceName = true
+		case afterService && afterServiceName && restartVerbs[token]:
+			return true
+		case binaryName != "" && base == binaryName && i+1 < len(tokens) && tokens[i+1] == "start":
+			return true
+		}
+	}
+	return false
+}
+
+// forEachMatchingLine calls the callback for each line matching, errors are ignored as the files are best-effort.
+func forEachMatchingLine(filePath string, match func(line string) bool, callback func(lineNo int, line string)) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		if match(scanner.Text()) {
+			callback(lineNo, scanner.Text())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import "testing"
+
+func TestCheckAutoRestartUnattendedUpgrades(t *testing.T) {
+	const periodic = "APT::Periodic::Update-Package-Lists \"1\";\nAPT::Periodic::Unattended-Upgrade \"1\";\n"
+	tests := []struct {
+		name     string
+		files    map[string]string
+		want     bool
+		wantLine int
+	}{
+		{
+			name: "flat",
+			files: map[string]string{
+				"apt/apt.conf.d/20auto-upgrades":       periodic,
+				"apt/apt.conf.d/50unattended-upgrades": "Unattended-Upgrade::Automatic-Reboot \"true\";\n",
+			},
+			want:     true,
+			wantLine: 1,
+		},
+		{
+			name: "nested",
+			files: map[string]string{
+				"apt/apt.conf.d/20auto-upgrades":       periodic,
+				"apt/apt.conf.d/50unattended-upgrades": "Unattended-Upgrade {\n\tMail \"root\";\n\tAutomatic-Reboot \"true\";\n};\n",
+			},
+			want:     true,
+			wantLine: 3,
+		},
+		{
+			name: "disabled by a later file",
+			files: map[string]string{
+				"apt/apt.conf.d/20auto-upgrades":       periodic,
+				"apt/apt.conf.d/50unattended-upgrades": "Unattended-Upgrade { Automatic-Reboot \"true\"; };\n",
+				"apt/apt.conf.d/99no-reboot":           "Unattended-Upgrade::Automatic-Reboot \"false\";\n",
+			},
+		},
+		{
+			name: "periodic upgrade disabled",
+			files: map[string]string{
+				"apt/apt.conf.d/20auto-upgrades":       "APT::Periodic { Unattended-Upgrade \"0\"; };\n",
+				"apt/apt.conf.d/50unattended-upgrades": "Unattended-Upgrade { Automatic-Reboot \"true\"; };\n",
+			},
+		},
+		{
+			name: "commented out",
+			files: map[string]string{
+				"apt/apt.conf.d/20auto-upgrades":       periodic,
+				"apt/apt.conf.d/50unattended-upgrades": "//Unattended-Upgrade::Automatic-Reboot \"true\";\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCheckRecords(t)
+
+			checkAutoRestartUnattendedUpgrades(writeEtcFiles(t, tt.files))
+
+			if !tt.want {
+				if len(checkRecords) > 0 {
+					t.Fatalf("want no record, got %s", checkRecords[0].message)
+				}
+				return
+			}
+			if len(checkRecords) != 1 || !checkRecords[0].fatal {
+				t.Fatalf("want a fatal record, got %d records", len(checkRecords))
+			}
+			if checkRecords[0].target.line != tt.wantLine {
+				t.Errorf("line = %d, want %d", checkRecords[0].target.line, tt.wantLine)
+			}
+		})
+	}
+}
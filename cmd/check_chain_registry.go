@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"math/big"
+	"path"
+	"strings"
+)
+
+// checkChainRegistry compares the node config with chain.json of the chain in the cosmos chain-registry,
+// returns the chain and path of the chain.json file.
+func checkChainRegistry(home string, nodeType types.NodeType, registryPath string, configToml *types.ConfigToml, appToml *types.AppToml) (*types.ChainRegistryChain, string) {
+	configPath := path.Join(home, "config")
+	genesisFilePath := path.Join(configPath, "genesis.json")
+
+	chainId, err := utils.ReadGenesisChainId(genesisFilePath)
+	if err != nil {
+		clientToml, errClientToml := readTomlSettings(path.Join(configPath, "client.toml"))
+		if errClientToml != nil || clientToml["chain-id"] == "" {
+			exitWithErrorMsgf("ERR: failed to read chain id from genesis and client.toml to match chain registry: %v\n", err)
+			return nil, ""
+		}
+		chainId = clientToml["chain-id"]
+	}
+
+	chain, chainFilePath, err := utils.LoadChainRegistryChain(registryPath, chainId)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to load chain registry: %v\n", err)
+		return nil, ""
+	}
+
+	checkChainRegistryPeers(path.Join(configPath, "config.toml"), configToml, chain, chainFilePath)
+	checkChainRegistryGasPrices(path.Join(configPath, "app.toml"), nodeType, appToml, chain, chainFilePath)
+	checkChainRegistryGenesisHash(genesisFilePath, chain, chainFilePath)
+
+	return chain, chainFilePath
+}
+
+// checkChainRegistryPeers compares seeds and persistent_peers with peers listed in the chain registry.
+// Entries not in the registry are fine, operators often use their own peers.
+func checkChainRegistryPeers(configTomlFilePath string, configToml *types.ConfigToml, chain *types.ChainRegistryChain, chainFilePath string) {
+	if chain.Peers == nil {
+		return
+	}
+
+	registryPeers := func(entries []types.ChainRegistryPeer) []*utils.Peer {
+		var peers []*utils.Peer
+		for _, entry := range entries {
+			// registry entries are not always well-formed, skip what CometBFT would not accept
+			if peer, err := utils.ParsePeer(entry.Id + "@" + entry.Address); err == nil {
+				peers = append(peers, peer)
+			}
+		}
+		return peers
+	}
+
+	for _, source := range []struct {
+		key           string
+		list          string
+		registryPeers []*utils.Peer
+	}{
+		{key: "seeds", list: configToml.P2P.Seeds, registryPeers: registryPeers(chain.Peers.Seeds)},
+		{key: "persistent_peers", list: configToml.P2P.PersistentPeers, registryPeers: registryPeers(chain.Peers.PersistentPeers)},
+	} {
+		target := settingTarget("config.toml", configTomlFilePath, "p2p."+source.key)
+		evaluatedRule(target)
+
+		registryPeerOfId := make(map[string]*utils.Peer)
+		for _, peer := range source.registryPeers {
+			registryPeerOfId[peer.Id] = peer
+		}
+
+		for _, entry := range utils.SplitPeerList(source.list) {
+			peer, err := utils.ParsePeer(entry)
+			if err != nil {
+				// reported by checkHomeConfigPeers
+				continue
+			}
+			if registryPeer, found := registryPeerOfId[peer.Id]; found && registryPeer.Address() != peer.Address() {
+				warnRecord(
+					target,
+					fmt.Sprintf("%s in %s has address %s but chain registry lists %s", peer.Id, source.key, peer.Address(), registryPeer.Address()),
+					fmt.Sprintf("use %s, or update %s if the registry is outdated", registryPeer.String(), chainFilePath),
+				)
+			}
+		}
+	}
+
+	if len(utils.SplitPeerList(configToml.P2P.Seeds)) == 0 {
+		var seeds []string
+		for _, peer := range registryPeers(chain.Peers.Seeds) {
+			seeds = append(seeds, peer.String())
+		}
+		if len(seeds) > 0 {
+			warnRecord(
+				settingTarget("config.toml", configTomlFilePath, "p2p.seeds"),
+				fmt.Sprintf("seeds is empty while chain registry lists %d seeds", len(seeds)),
+				fmt.Sprintf("seeds = \"%s\"", strings.Join(seeds, ",")),
+			)
+		}
+	}
+}
+
+// checkChainRegistryGasPrices compares minimum-gas-prices with fee tokens of the chain registry,
+// wallets pick the low/average gas price, transactions paying less than minimum-gas-prices are rejected by the node.
+func checkChainRegistryGasPrices(appTomlFilePath string, nodeType types.NodeType, appToml *types.AppToml, chain *types.ChainRegistryChain, chainFilePath string) {
+	if chain.Fees == nil || len(chain.Fees.FeeTokens) == 0 {
+		return
+	}
+	gasPrices, err := utils.ParseDecCoins(appToml.MinimumGasPrices)
+	if err != nil || len(gasPrices) == 0 {
+		// empty and malformed values are reported by checkMinimumGasPrices
+		return
+	}
+
+	target := settingTarget("app.toml", appTomlFilePath, "minimum-gas-prices")
+	evaluatedRule(target)
+	servesWallets := nodeType == types.RpcNode || nodeType == types.ArchivalNode
+
+	var feeDenoms []string
+	for _, feeToken := range chain.Fees.FeeTokens {
+		feeDenoms = append(feeDenoms, feeToken.Denom)
+	}
+
+	for _, gasPrice := range gasPrices {
+		feeToken := chain.FeeToken(gasPrice.Denom)
+		if feeToken == nil {
+			warnRecord(
+				target,
+				fmt.Sprintf("denom %s of minimum-gas-prices is not a fee token in chain registry, fee tokens: %s", gasPrice.Denom, strings.Join(feeDenoms, ", ")),
+				fmt.Sprintf("use fee tokens of the chain, or update %s if the registry is outdated", chainFilePath),
+			)
+			continue
+		}
+
+		price := gasPrice.Rat()
+		if fixedMinGasPrice := registryGasPrice(feeToken.FixedMinGasPrice); fixedMinGasPrice != nil && price.Cmp(fixedMinGasPrice) < 0 {
+			warnRecord(
+				target,
+				fmt.Sprintf("minimum-gas-prices %s is lower than fixed_min_gas_price %s%s of chain registry", gasPrice, feeToken.FixedMinGasPrice, feeToken.Denom),
+				fmt.Sprintf("set at least %s%s", feeToken.FixedMinGasPrice, feeToken.Denom),
+			)
+		}
+		if averageGasPrice := registryGasPrice(feeToken.AverageGasPrice); averageGasPrice != nil && price.Cmp(averageGasPrice) > 0 {
+			warnRecord(
+				target,
+				fmt.Sprintf("minimum-gas-prices %s is higher than average_gas_price %s%s of chain registry, transactions using the average gas price of wallets are rejected", gasPrice, feeToken.AverageGasPrice, feeToken.Denom),
+				fmt.Sprintf("set at most %s%s", feeToken.AverageGasPrice, feeToken.Denom),
+			)
+		} else if lowGasPrice := registryGasPrice(feeToken.LowGasPrice); servesWallets && lowGasPrice != nil && price.Cmp(lowGasPrice) > 0 {
+			warnRecord(
+				target,
+				fmt.Sprintf("minimum-gas-prices %s is higher than low_gas_price %s%s of chain registry, transactions using the low gas price of wallets are rejected", gasPrice, feeToken.LowGasPrice, feeToken.Denom),
+				fmt.Sprintf("set at most %s%s for a node serving wallets", feeToken.LowGasPrice, feeToken.Denom),
+			)
+		}
+	}
+}
+
+// registryGasPrice returns nil when the price is not listed or not a number.
+func registryGasPrice(price json.Number) *big.Rat {
+	if price == "" {
+		return nil
+	}
+	rat, ok := new(big.Rat).SetString(string(price))
+	if !ok {
+		return nil
+	}
+	return rat
+}
+
+// checkChainRegistryGenesisHash compares SHA256 of genesis.json with codebase.genesis.sha256 of chain.json.
+// The registry does not publish a hash of genesis, the field is added locally by the operator.
+func checkChainRegistryGenesisHash(genesisFilePath string, chain *types.ChainRegistryChain, chainFilePath string) {
+	if chain.Codebase == nil || chain.Codebase.Genesis == nil || chain.Codebase.Genesis.Sha256 == "" {
+		return
+	}
+
+	hash, err := sha256File(genesisFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to hash genesis.json file at %s: %v\n", genesisFilePath, err)
+		return
+	}
+	evaluatedRule(fileTarget("genesis.json", genesisFilePath, "chain-registry"))
+	if !strings.EqualFold(hash, chain.Codebase.Genesis.Sha256) {
+		suggest := fmt.Sprintf("download genesis.json listed in %s", chainFilePath)
+		if chain.Codebase.Genesis.GenesisUrl != "" {
+			suggest = fmt.Sprintf("download genesis.json from %s", chain.Codebase.Genesis.GenesisUrl)
+		}
+		fatalRecord(
+			fileTarget("genesis.json", genesisFilePath, "chain-registry"),
+			fmt.Sprintf("SHA256 of genesis.json is %s, does not match %s of chain registry", hash, chain.Codebase.Genesis.Sha256),
+			suggest,
+		)
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/bcdevtools/node-setup-check/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newRegistryChain() *types.ChainRegistryChain {
+	return &types.ChainRegistryChain{
+		ChainName: "mychain",
+		ChainId:   "mychain-1",
+		Fees: &types.ChainRegistryFees{
+			FeeTokens: []types.ChainRegistryFeeToken{
+				{Denom: "uatom", FixedMinGasPrice: "0.005", LowGasPrice: "0.01", AverageGasPrice: "0.025", HighGasPrice: "0.03"},
+				{Denom: "ustake"},
+			},
+		},
+		Peers: &types.ChainRegistryPeers{
+			Seeds: []types.ChainRegistryPeer{
+				{Id: "ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0", Address: "seeds.polkachu.com:14956"},
+				{Id: "not-a-node-id", Address: "broken.example.com:26656"},
+			},
+			PersistentPeers: []types.ChainRegistryPeer{
+				{Id: "ee27245d88c632a556cf72cc7f3587380c09b469", Address: "45.79.249.253:26656"},
+			},
+		},
+	}
+}
+
+// recordMessages returns messages of the records of the rule.
+func recordMessages(rule string) []string {
+	var messages []string
+	for _, record := range checkRecords {
+		if record.target.rule == rule {
+			messages = append(messages, record.message)
+		}
+	}
+	return messages
+}
+
+func TestCheckChainRegistryPeers(t *testing.T) {
+	tests := []struct {
+		name            string
+		seeds           string
+		persistentPeers string
+		wantSeeds       []string // substrings of the messages
+		wantPeers       []string
+	}{
+		{
+			name:            "same as registry",
+			seeds:           "ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0@seeds.polkachu.com:14956",
+			persistentPeers: "ee27245d88c632a556cf72cc7f3587380c09b469@45.79.249.253:26656",
+		},
+		{
+			name:            "own peers not in registry",
+			seeds:           "65b60673d6ed884bf01c2c222d82ada0740f29ac@10.0.0.5:26656",
+			persistentPeers: "65b60673d6ed884bf01c2c222d82ada0740f29ac@10.0.0.5:26656",
+		},
+		{
+			name:            "address mismatch",
+			seeds:           "ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0@1.2.3.4:14956",
+			persistentPeers: "ee27245d88c632a556cf72cc7f3587380c09b469@45.79.249.253:26657",
+			wantSeeds:       []string{"ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0 in seeds has address 1.2.3.4:14956 but chain registry lists seeds.polkachu.com:14956"},
+			wantPeers:       []string{"ee27245d88c632a556cf72cc7f3587380c09b469 in persistent_peers has address 45.79.249.253:26657 but chain registry lists 45.79.249.253:26656"},
+		},
+		{
+			name:            "empty seeds",
+			persistentPeers: "ee27245d88c632a556cf72cc7f3587380c09b469@45.79.249.253:26656",
+			wantSeeds:       []string{"seeds is empty while chain registry lists 1 seeds"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCheckRecords(t)
+
+			configToml := &types.ConfigToml{P2P: &types.P2pConfigToml{Seeds: tt.seeds, PersistentPeers: tt.persistentPeers}}
+			checkChainRegistryPeers("config.toml", configToml, newRegistryChain(), "chain.json")
+
+			assertRecordMessages(t, "config.toml/p2p.seeds", tt.wantSeeds)
+			assertRecordMessages(t, "config.toml/p2p.persistent_peers", tt.wantPeers)
+		})
+	}
+}
+
+func TestCheckChainRegistryGasPrices(t *testing.T) {
+	tests := []struct {
+		name             string
+		nodeType         types.NodeType
+		minimumGasPrices string
+		want             []string // substrings of the messages
+	}{
+		{name: "between fixed min and low", nodeType: types.RpcNode, minimumGasPrices: "0.008uatom"},
+		{name: "equals low", nodeType: types.RpcNode, minimumGasPrices: "0.01uatom"},
+		{name: "fee token without prices", nodeType: types.RpcNode, minimumGasPrices: "0.01uatom,1ustake"},
+		{name: "not a fee token", nodeType: types.ValidatorNode, minimumGasPrices: "0.01uatom,0.1uosmo", want: []string{"denom uosmo of minimum-gas-prices is not a fee token"}},
+		{name: "lower than fixed min", nodeType: types.ValidatorNode, minimumGasPrices: "0.001uatom", want: []string{"lower than fixed_min_gas_price 0.005uatom"}},
+		{name: "higher than average", nodeType: types.ValidatorNode, minimumGasPrices: "0.03uatom", want: []string{"higher than average_gas_price 0.025uatom"}},
+		{name: "higher than low on RPC", nodeType: types.RpcNode, minimumGasPrices: "0.02uatom", want: []string{"higher than low_gas_price 0.01uatom"}},
+		{name: "higher than low on archival", nodeType: types.ArchivalNode, minimumGasPrices: "0.02uatom", want: []string{"higher than low_gas_price 0.01uatom"}},
+		{name: "higher than low on validator", nodeType: types.ValidatorNode, minimumGasPrices: "0.02uatom"},
+		{name: "malformed", nodeType: types.RpcNode, minimumGasPrices: "uatom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCheckRecords(t)
+
+			checkChainRegistryGasPrices("app.toml", tt.nodeType, &types.AppToml{MinimumGasPrices: tt.minimumGasPrices}, newRegistryChain(), "chain.json")
+
+			assertRecordMessages(t, "app.toml/minimum-gas-prices", tt.want)
+		})
+	}
+}
+
+func TestCheckChainRegistryGenesisHash(t *testing.T) {
+	genesisFilePath := filepath.Join(t.TempDir(), "genesis.json")
+	if err := os.WriteFile(genesisFilePath, []byte(`{"chain_id":"mychain-1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := sha256File(genesisFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		genesis   string // codebase.genesis of chain.json
+		wantRule  bool
+		wantFatal bool
+	}{
+		{name: "registry schema has no hash", genesis: `{"name": "v1", "genesis_url": "https://example.com/genesis.json"}`},
+		{name: "matched", genesis: `{"genesis_url": "https://example.com/genesis.json", "sha256": "` + strings.ToUpper(hash) + `"}`, wantRule: true},
+		{name: "mismatched", genesis: `{"genesis_url": "https://example.com/genesis.json", "sha256": "` + strings.Repeat("0", 64) + `"}`, wantRule: true, wantFatal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCheckRecords(t)
+
+			chain := newRegistryChain()
+			if err := json.Unmarshal([]byte(`{"genesis": `+tt.genesis+`}`), &chain.Codebase); err != nil {
+				t.Fatal(err)
+			}
+			checkChainRegistryGenesisHash(genesisFilePath, chain, "chain.json")
+
+			var evaluated bool
+			for _, rule := range evaluatedRules {
+				evaluated = evaluated || rule == "genesis.json/chain-registry"
+			}
+			if evaluated != tt.wantRule {
+				t.Errorf("rule evaluated = %t, want %t", evaluated, tt.wantRule)
+			}
+			if tt.wantFatal {
+				if len(checkRecords) != 1 || !checkRecords[0].fatal {
+					t.Errorf("want a fatal record, got %v", recordMessages("genesis.json/chain-registry"))
+				}
+			} else if len(checkRecords) > 0 {
+				t.Errorf("want no record, got %v", recordMessages("genesis.json/chain-registry"))
+			}
+		})
+	}
+}
+
+func assertRecordMessages(t *testing.T, rule string, want []string) {
+	t.Helper()
+
+	messages := recordMessages(rule)
+	if len(messages) != len(want) {
+		t.Fatalf("%s: want %d records, got %v", rule, len(want), messages)
+	}
+	for i, message := range messages {
+		if !strings.Contains(message, want[i]) {
+			t.Errorf("%s: record %q does not contain %q", rule, message, want[i])
+		}
+	}
+}
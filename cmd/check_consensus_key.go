@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// consensusKey is the decoded priv_validator_key.json.
+type consensusKey struct {
+	pubKeyType string
+	pubKey     []byte
+	address    []byte
+	valcons    string // empty when bech32 prefix is unknown
+
+	genesisMatches []genesisValidatorMatch
+}
+
+// genesisValidatorMatch is where the consensus key is found in genesis.
+type genesisValidatorMatch struct {
+	source          string
+	moniker         string
+	operatorAddress string
+}
+
+// checkConsensusKey decodes priv_validator_key.json, the private key must yield the stated pub_key and address,
+// and the key type must be allowed by consensus params of genesis.
+// Prefix of the valcons address is taken from bech32Prefix, or from genesis accounts when empty.
+// The key is also located in the genesis validator set and gentxs, non-validator must not hold a genesis validator key.
+func checkConsensusKey(home string, nodeType types.NodeType, bech32Prefix string, genesis *types.Genesis) *consensusKey {
+	configPath := path.Join(home, "config")
+	privValidatorJsonFilePath := path.Join(configPath, "priv_validator_key.json")
+	keyTarget := fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "consensus-key")
+	evaluatedRule(keyTarget)
+
+	bz, err := os.ReadFile(privValidatorJsonFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read priv_validator_key.json file at %s: %v\n", privValidatorJsonFilePath, err)
+		return nil
+	}
+	var pvKey struct {
+		PrivKey struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"priv_key"`
+		PubKey struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"pub_key"`
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(bz, &pvKey); err != nil {
+		exitWithErrorMsgf("ERR: failed to unmarshal priv_validator_key.json file at %s: %v\n", privValidatorJsonFilePath, err)
+		return nil
+	}
+
+	privKey, err := base64.StdEncoding.DecodeString(pvKey.PrivKey.Value)
+	if err != nil {
+		fatalRecord(keyTarget, "value of priv_key in priv_validator_key.json is not valid base64", "restore priv_validator_key.json from backup")
+		return nil
+	}
+	pubKeyType, pubKey, err := utils.DeriveConsensusPubKey(pvKey.PrivKey.Type, privKey)
+	if err != nil {
+		fatalRecord(keyTarget, fmt.Sprintf("invalid priv_key in priv_validator_key.json: %v", err), "restore priv_validator_key.json from backup")
+		return nil
+	}
+
+	allowedKeyTypes := genesis.ValidatorPubKeyTypes()
+	if abciType := utils.ConsensusKeyAbciType(pubKeyType); !slices.Contains(allowedKeyTypes, abciType) {
+		fatalRecord(
+			keyTarget,
+			fmt.Sprintf("consensus key type %s is not allowed by the chain, genesis allows: %s", abciType, strings.Join(allowedKeyTypes, ", ")),
+			"generate the consensus key with an allowed type",
+		)
+	}
+
+	if pvKey.PubKey.Type != pubKeyType {
+		fatalRecord(keyTarget, fmt.Sprintf("type of pub_key is %s, expected %s for priv_key type %s", pvKey.PubKey.Type, pubKeyType, pvKey.PrivKey.Type), "restore priv_validator_key.json from backup")
+	}
+	if statedPubKey, err := base64.StdEncoding.DecodeString(pvKey.PubKey.Value); err != nil || !bytes.Equal(statedPubKey, pubKey) {
+		fatalRecord(
+			keyTarget,
+			fmt.Sprintf("pub_key %s in priv_validator_key.json does not belong to the priv_key, expected %s", pvKey.PubKey.Value, base64.StdEncoding.EncodeToString(pubKey)),
+			"restore priv_validator_key.json from backup",
+		)
+	}
+
+	address := utils.ConsensusAddress(pubKeyType, pubKey)
+	if !strings.EqualFold(pvKey.Address, hex.EncodeToString(address)) {
+		fatalRecord(
+			keyTarget,
+			fmt.Sprintf("address %s in priv_validator_key.json does not match the key, expected %X", pvKey.Address, address),
+			"restore priv_validator_key.json from backup",
+		)
+	}
+
+	key := &consensusKey{
+		pubKeyType: pubKeyType,
+		pubKey:     pubKey,
+		address:    address,
+	}
+
+	key.genesisMatches = locateConsensusKeyInGenesis(genesis, key)
+	if nodeType != types.ValidatorNode {
+		evaluatedRule(fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "genesis-validator"))
+	}
+	if len(key.genesisMatches) > 0 && nodeType != types.ValidatorNode {
+		match := key.genesisMatches[0]
+		warnRecord(
+			fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "genesis-validator"),
+			fmt.Sprintf("node is not a validator but holds consensus key of genesis validator %s (found in %s), running it along with the validator can double sign", match.String(), match.source),
+			"replace priv_validator_key.json with a newly generated key, unless this node is going to be the validator",
+		)
+	}
+
+	if bech32Prefix == "" {
+		for _, accountAddress := range genesis.AccountAddresses() {
+			if hrp, err := utils.Bech32Hrp(accountAddress); err == nil {
+				bech32Prefix = hrp
+				break
+			}
+		}
+	}
+	if bech32Prefix != "" {
+		key.valcons, err = utils.Bech32Encode(bech32Prefix+"valcons", address)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to encode valcons address with prefix %s: %v\n", bech32Prefix, err)
+			return nil
+		}
+	}
+
+	return key
+}
+
+// locateConsensusKeyInGenesis finds the key in the initial validator set, gentxs and validators of staking genesis.
+func locateConsensusKeyInGenesis(genesis *types.Genesis, key *consensusKey) []genesisValidatorMatch {
+	pubKeyValue := base64.StdEncoding.EncodeToString(key.pubKey)
+	protoType := utils.ConsensusKeyProtoType(key.pubKeyType)
+	isSameAnyPubKey := func(pubKey *types.GenesisAnyPubKey) bool {
+		return pubKey != nil && pubKey.Type == protoType && pubKey.Key == pubKeyValue
+	}
+
+	var matches []genesisValidatorMatch
+	for _, validator := range genesis.InitialValidators() {
+		if validator.PubKey.Type == key.pubKeyType && validator.PubKey.Value == pubKeyValue {
+			matches = append(matches, genesisValidatorMatch{
+				source:  "validators",
+				moniker: validator.Name,
+			})
+		}
+	}
+	if genesis.AppState != nil && genesis.AppState.Genutil != nil {
+		for _, genTx := range genesis.AppState.Genutil.GenTxs {
+			for _, msg := range genTx.Body.Messages {
+				if isSameAnyPubKey(msg.PubKey) {
+					matches = append(matches, genesisValidatorMatch{
+						source:          "app_state.genutil.gen_txs",
+						moniker:         msg.Description.Moniker,
+						operatorAddress: msg.ValidatorAddress,
+					})
+				}
+			}
+		}
+	}
+	if genesis.AppState != nil && genesis.AppState.Staking != nil {
+		for _, validator := range genesis.AppState.Staking.Validators {
+			if isSameAnyPubKey(validator.ConsensusPubKey) {
+				matches = append(matches, genesisValidatorMatch{
+					source:          "app_state.staking.validators",
+					moniker:         validator.Description.Moniker,
+					operatorAddress: validator.OperatorAddress,
+				})
+			}
+		}
+	}
+
+	// initial validator set has no operator address, take from the other sources
+	for i := range matches {
+		for _, other := range matches {
+			if matches[i].operatorAddress == "" && other.operatorAddress != "" {
+				matches[i].operatorAddress = other.operatorAddress
+			}
+			if matches[i].moniker == "" && other.moniker != "" {
+				matches[i].moniker = other.moniker
+			}
+		}
+	}
+
+	return matches
+}
+
+func (m genesisValidatorMatch) String() string {
+	moniker := m.moniker
+	if moniker == "" {
+		moniker = "(no moniker)"
+	}
+	if m.operatorAddress == "" {
+		return moniker
+	}
+	return fmt.Sprintf("%s %s", moniker, m.operatorAddress)
+}
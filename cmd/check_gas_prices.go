@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"math/big"
+	"path"
+	"sort"
+	"strings"
+)
+
+// checkMinimumGasPrices parses minimum-gas-prices as DecCoins, the node refuses to start when it is malformed or has duplicated denoms.
+func checkMinimumGasPrices(appTomlFilePath string, isValidator bool, minimumGasPrices string) {
+	target := settingTarget("app.toml", appTomlFilePath, "minimum-gas-prices")
+	evaluatedRule(target)
+
+	if strings.TrimSpace(minimumGasPrices) == "" {
+		if isValidator {
+			warnRecord(target, "minimum-gas-prices is empty, validator must set, in app.toml file", "")
+		} else {
+			warnRecord(target, "minimum-gas-prices is empty in app.toml file", "")
+		}
+		return
+	}
+
+	gasPrices, err := utils.ParseDecCoins(minimumGasPrices)
+	if err != nil {
+		fatalRecord(target, fmt.Sprintf("minimum-gas-prices is malformed in app.toml file: %v", err), "comma-separated <amount><denom> like minimum-gas-prices = \"0.025uatom\"")
+		return
+	}
+
+	seenDenoms := make(map[string]bool)
+	for _, gasPrice := range gasPrices {
+		if seenDenoms[gasPrice.Denom] {
+			fatalRecord(target, fmt.Sprintf("denom %s is duplicated in minimum-gas-prices of app.toml file: %s", gasPrice.Denom, minimumGasPrices), "keep only one price for each denom")
+			return
+		}
+		seenDenoms[gasPrice.Denom] = true
+	}
+
+	isSorted := sort.SliceIsSorted(gasPrices, func(i, j int) bool {
+		return gasPrices[i].Denom < gasPrices[j].Denom
+	})
+	if !isSorted {
+		sortedGasPrices := append([]utils.DecCoin{}, gasPrices...)
+		sort.Slice(sortedGasPrices, func(i, j int) bool {
+			return sortedGasPrices[i].Denom < sortedGasPrices[j].Denom
+		})
+		suggestGasPrices := make([]string, 0, len(sortedGasPrices))
+		for _, gasPrice := range sortedGasPrices {
+			suggestGasPrices = append(suggestGasPrices, gasPrice.String())
+		}
+		fatalRecord(target, fmt.Sprintf("minimum-gas-prices is not sorted by denom in app.toml file: %s", minimumGasPrices), fmt.Sprintf("minimum-gas-prices = \"%s\"", strings.Join(suggestGasPrices, ",")))
+	}
+
+	isZero := true
+	for _, gasPrice := range gasPrices {
+		if gasPrice.Rat().Sign() != 0 {
+			isZero = false
+			break
+		}
+	}
+	if isZero {
+		if isValidator {
+			warnRecord(target, fmt.Sprintf("minimum-gas-prices is zero, validator must set, in app.toml file: %s", minimumGasPrices), "")
+		} else {
+			warnRecord(target, fmt.Sprintf("minimum-gas-prices is zero in app.toml file: %s", minimumGasPrices), "")
+		}
+	}
+}
+
+// checkMinimumGasPricesWithGenesis cross-checks denoms of minimum-gas-prices with fee denoms of genesis,
+// and the price of the EVM denom with min_gas_price of feemarket.
+func checkMinimumGasPricesWithGenesis(configPath string, genesis *types.Genesis, minimumGasPrices string) {
+	gasPrices, err := utils.ParseDecCoins(minimumGasPrices)
+	if err != nil || len(gasPrices) == 0 {
+		// reported by checkMinimumGasPrices
+		return
+	}
+
+	target := settingTarget("app.toml", path.Join(configPath, "app.toml"), "minimum-gas-prices")
+	evaluatedRule(target)
+
+	feeDenoms := genesis.FeeDenoms()
+	if len(feeDenoms) > 0 {
+		for _, gasPrice := range gasPrices {
+			if strings.HasPrefix(gasPrice.Denom, "ibc/") {
+				// IBC denoms appear after genesis, accepted by chains which allow fees in other tokens
+				continue
+			}
+			if !isFeeDenom(feeDenoms, gasPrice.Denom) {
+				warnRecord(
+					target,
+					fmt.Sprintf("denom %s of minimum-gas-prices is not known by genesis, fee denoms: %s", gasPrice.Denom, strings.Join(feeDenoms, ", ")),
+					"use denoms of the chain, base denom instead of display denom like uatom instead of atom",
+				)
+			}
+		}
+	}
+
+	checkMinimumGasPricesWithFeemarket(target, genesis, gasPrices)
+}
+
+// checkMinimumGasPricesWithFeemarket warns when price of the EVM denom is lower than min_gas_price of feemarket,
+// the node would accept transactions into mempool which cannot be included in a block.
+func checkMinimumGasPricesWithFeemarket(target recordTarget, genesis *types.Genesis, gasPrices []utils.DecCoin) {
+	if genesis.AppState == nil || genesis.AppState.Feemarket == nil || genesis.AppState.Feemarket.Params == nil {
+		return
+	}
+	feemarketParams := genesis.AppState.Feemarket.Params
+	// LegacyDec in genesis has 18 trailing decimal places, e.g. 20000000000.000000000000000000
+	minGasPrice := feemarketParams.MinGasPrice
+	if strings.Contains(minGasPrice, ".") {
+		minGasPrice = strings.TrimSuffix(strings.TrimRight(minGasPrice, "0"), ".")
+	}
+	feemarketMinGasPrice, ok := new(big.Rat).SetString(minGasPrice)
+	if !ok || feemarketMinGasPrice.Sign() == 0 {
+		return
+	}
+	denom := feemarketParams.FeeDenom
+	if denom == "" {
+		denom = genesis.EvmDenom()
+	}
+
+	for _, gasPrice := range gasPrices {
+		if gasPrice.Denom != denom {
+			continue
+		}
+		if gasPrice.Rat().Cmp(feemarketMinGasPrice) < 0 {
+			warnRecord(
+				target,
+				fmt.Sprintf("minimum-gas-prices %s is lower than min_gas_price %s%s of feemarket in genesis", gasPrice, minGasPrice, denom),
+				fmt.Sprintf("set at least %s%s, unless min_gas_price has been changed by governance", minGasPrice, denom),
+			)
+		}
+	}
+}
+
+func isFeeDenom(feeDenoms []string, denom string) bool {
+	for _, feeDenom := range feeDenoms {
+		if feeDenom == denom {
+			return true
+		}
+	}
+	return false
+}
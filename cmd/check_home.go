@@ -22,13 +22,14 @@ func checkHome(home string) {
 	}
 
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("home", home, "permission"))
 	if filePerm.Other.Write {
-		fatalRecord("home directory is writable by others", fmt.Sprintf("chmod o-w %s", home))
+		fatalRecord(fileTarget("home", home, "permission"), "home directory is writable by others", fmt.Sprintf("chmod o-w %s", home))
 	}
 	if filePerm.Group.Write {
-		fatalRecord("home directory is writable by group", fmt.Sprintf("chmod g-w %s", home))
+		fatalRecord(fileTarget("home", home, "permission"), "home directory is writable by group", fmt.Sprintf("chmod g-w %s", home))
 	}
 	if !filePerm.User.IsFullPermission() {
-		fatalRecord("home directory is fully accessible by user", fmt.Sprintf("chmod u+rwx %s", home))
+		fatalRecord(fileTarget("home", home, "permission"), "home directory is fully accessible by user", fmt.Sprintf("chmod u+rwx %s", home))
 	}
 }
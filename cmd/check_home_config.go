@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/bcdevtools/node-setup-check/constants"
@@ -14,40 +15,64 @@ import (
 	"strings"
 )
 
-func checkHomeConfig(home string, nodeType types.NodeType) {
+// checkHomeConfig checks the config directory, returns the parsed config.toml, app.toml, genesis and the node ID.
+func checkHomeConfig(home string, nodeType types.NodeType, remoteSigner bool, privateNetwork bool) (*types.ConfigToml, *types.AppToml, *types.Genesis, string) {
 	configPath := path.Join(home, "config")
 	perm, exists, isDir, err := utils.FileInfo(configPath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to check config directory at %s: %v\n", configPath, err)
-		return
+		return nil, nil, nil, ""
 	}
 	if !exists {
 		exitWithErrorMsgf("ERR: config directory does not exist: %s\n", configPath)
-		return
+		return nil, nil, nil, ""
 	}
 	if !isDir {
 		exitWithErrorMsgf("ERR: config is not a directory: %s\n", configPath)
-		return
+		return nil, nil, nil, ""
 	}
 
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("config-dir", configPath, "permission"))
 	if filePerm.Other.Write {
-		fatalRecord("config directory is writable by others", "chmod o-w "+configPath)
+		fatalRecord(fileTarget("config-dir", configPath, "permission"), "config directory is writable by others", "chmod o-w "+configPath)
 	}
 	if filePerm.Group.Write {
-		fatalRecord("config directory is writable by group", "chmod g-w "+configPath)
+		fatalRecord(fileTarget("config-dir", configPath, "permission"), "config directory is writable by group", "chmod g-w "+configPath)
 	}
 	if !filePerm.User.IsFullPermission() {
-		fatalRecord("config directory is not fully accessible by user", "chmod u+rwx "+configPath)
+		fatalRecord(fileTarget("config-dir", configPath, "permission"), "config directory is not fully accessible by user", "chmod u+rwx "+configPath)
 	}
 
 	appToml := checkHomeConfigAppToml(configPath, nodeType)
 	checkHomeConfigClientToml(configPath)
 	configToml := checkHomeConfigConfigToml(configPath, nodeType)
-	checkHomeConfigGenesisJson(configPath)
-	checkHomeConfigNodeKeyJson(configPath)
-	checkHomeConfigPrivValidatorKeyJson(configPath)
-	checkHomeConfigConfigTomlAndAppToml(nodeType, configToml, appToml)
+	checkHomeConfigPeers(configPath, configToml, privateNetwork)
+	genesis := checkHomeConfigGenesisJson(configPath)
+	checkMinimumGasPricesWithGenesis(configPath, genesis, appToml.MinimumGasPrices)
+	nodeId := checkHomeConfigNodeKeyJson(configPath)
+	if nodeId != "" {
+		checkHomeConfigSelfPeering(configPath, configToml, nodeId)
+	}
+	checkHomeConfigAddrBook(configPath, nodeType, configToml, nodeId, privateNetwork)
+	if remoteSigner {
+		checkHomeConfigRemoteSigner(configPath, configToml)
+	} else {
+		checkHomeConfigPrivValidatorKeyJson(configPath)
+		if nodeType == types.ValidatorNode {
+			evaluatedRule(settingTarget("config.toml", path.Join(configPath, "config.toml"), "priv_validator_laddr"))
+		}
+		if nodeType == types.ValidatorNode && configToml.PrivValidatorLaddr != "" {
+			warnRecord(
+				settingTarget("config.toml", path.Join(configPath, "config.toml"), "priv_validator_laddr"),
+				"priv_validator_laddr is set in config.toml, node is using remote signer instead of priv_validator_key.json",
+				"provide --remote-signer to check remote signer setup",
+			)
+		}
+	}
+	checkHomeConfigConfigTomlAndAppToml(configPath, nodeType, configToml, appToml)
+
+	return configToml, appToml, genesis, nodeId
 }
 
 func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.AppToml {
@@ -56,6 +81,9 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	isSnapshotNode := nodeType == types.SnapshotNode
 	isArchivalNode := nodeType == types.ArchivalNode
 	appTomlFilePath := path.Join(configPath, "app.toml")
+	appTomlSetting := func(key string) recordTarget {
+		return settingTarget("app.toml", appTomlFilePath, key)
+	}
 	perm, exists, isDir, err := utils.FileInfo(appTomlFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to check app.toml file at %s: %v\n", appTomlFilePath, err)
@@ -70,17 +98,18 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 		return nil
 	}
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("app.toml", appTomlFilePath, "permission"))
 	if filePerm.Other.Write {
-		fatalRecord("app.toml file is writable by others", "chmod 644 "+appTomlFilePath)
+		fatalRecord(fileTarget("app.toml", appTomlFilePath, "permission"), "app.toml file is writable by others", "chmod 644 "+appTomlFilePath)
 	}
 	if filePerm.Group.Write {
-		fatalRecord("app.toml file is writable by group", "chmod 644 "+appTomlFilePath)
+		fatalRecord(fileTarget("app.toml", appTomlFilePath, "permission"), "app.toml file is writable by group", "chmod 644 "+appTomlFilePath)
 	}
 	if !filePerm.User.Read {
-		fatalRecord("app.toml file is not readable by user", "chmod 644 "+appTomlFilePath)
+		fatalRecord(fileTarget("app.toml", appTomlFilePath, "permission"), "app.toml file is not readable by user", "chmod 644 "+appTomlFilePath)
 	}
 	if !filePerm.User.Write {
-		fatalRecord("app.toml file is not writable by user", "chmod 644 "+appTomlFilePath)
+		fatalRecord(fileTarget("app.toml", appTomlFilePath, "permission"), "app.toml file is not writable by user", "chmod 644 "+appTomlFilePath)
 	}
 
 	bz, err := os.ReadFile(appTomlFilePath)
@@ -96,19 +125,25 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 		return nil
 	}
 
-	if app.MinimumGasPrices == "" {
-		if isValidator {
-			warnRecord("minimum-gas-prices is empty, validator must set, in app.toml file", "")
-		} else {
-			warnRecord("minimum-gas-prices is empty in app.toml file", "")
-		}
-	} else if regexp.MustCompile(`^\s*0[a-z]+\s*$`).MatchString(app.MinimumGasPrices) {
-		if isValidator {
-			warnRecord(fmt.Sprintf("minimum-gas-prices is zero, validator must set, in app.toml file: %s", app.MinimumGasPrices), "")
-		} else {
-			warnRecord(fmt.Sprintf("minimum-gas-prices is zero in app.toml file: %s", app.MinimumGasPrices), "")
-		}
-	}
+	evaluatedRule(
+		appTomlSetting("pruning"),
+		appTomlSetting("pruning-keep-recent"),
+		appTomlSetting("pruning-interval"),
+		appTomlSetting("halt-height"),
+		appTomlSetting("halt-time"),
+		appTomlSetting("min-retain-blocks"),
+		appTomlSetting("api.enable"),
+		appTomlSetting("api.swagger"),
+		appTomlSetting("json-rpc.enable"),
+		appTomlSetting("json-rpc.enable-indexer"),
+		appTomlSetting("state-sync.snapshot-interval"),
+		appTomlSetting("state-sync.snapshot-keep-recent"),
+		appTomlSetting("grpc.enable"),
+		appTomlSetting("grpc.max-send-msg-size"),
+		appTomlSetting("grpc.address"),
+	)
+
+	checkMinimumGasPrices(appTomlFilePath, isValidator, app.MinimumGasPrices)
 
 	const recommendPruningCustomKeepRecent = 362880
 
@@ -116,16 +151,19 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	case constants.PruningDefault:
 		if isValidator {
 			warnRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'default' in app.toml file",
 				fmt.Sprintf("set pruning to 'custom' %d/10", recommendPruningCustomKeepRecent),
 			)
 		} else if isSnapshotNode {
 			warnRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'default' in app.toml file, snapshot not should be configured properly for snapshot purpose",
 				fmt.Sprintf("set pruning to 'custom' 100/10"),
 			)
 		} else if isArchivalNode {
 			fatalRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'default' in app.toml file, archival node must be configured properly for archival purpose",
 				"set pruning to 'nothing'",
 			)
@@ -133,11 +171,13 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	case constants.PruningNothing:
 		if isValidator {
 			fatalRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'nothing' in app.toml file, validator should not use this option",
 				fmt.Sprintf("set pruning to 'custom' %d/10", recommendPruningCustomKeepRecent),
 			)
 		} else if isSnapshotNode {
 			fatalRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'nothing' in app.toml file, snapshot not should be configured properly for snapshot purpose",
 				"set pruning to 'custom' 100/10",
 			)
@@ -145,6 +185,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	case constants.PruningEverything:
 		if isValidator {
 			warnRecord(
+				appTomlSetting("pruning"),
 				fmt.Sprintf(
 					"pruning set to 'everything', however to work properly with double_sign_check_height, it should be set to 'custom' at least %d/10 in app.toml file",
 					constants.RecommendDoubleSignCheckHeight+10,
@@ -152,6 +193,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 				fmt.Sprintf("set pruning = 'custom', pruning-keep-recent = at least double_sign_check_height + 10 or recommend %d, pruning-interval = 10", recommendPruningCustomKeepRecent),
 			)
 			warnRecord(
+				appTomlSetting("pruning"),
 				fmt.Sprintf(
 					"pruning set to 'everything', however to work properly with evident, it should be set to 'custom' %d/10 in app.toml file",
 					recommendPruningCustomKeepRecent,
@@ -160,38 +202,42 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 			)
 		} else if isArchivalNode {
 			fatalRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'everything' in app.toml file, archival node must not use this option",
 				"set pruning to 'nothing'",
 			)
 		} else if isSnapshotNode {
 			fatalRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'everything' in app.toml file, snapshot node must not use this option",
 				"set pruning to 'custom' 100/10",
 			)
 		} else {
 			fatalRecord(
+				appTomlSetting("pruning"),
 				"pruning set to 'everything' in app.toml file, non-validator should not use this option",
 				fmt.Sprintf("set pruning to 'custom' %d/10", recommendPruningCustomKeepRecent),
 			)
 		}
 	case constants.PruningCustom:
 		if isArchivalNode {
-			fatalRecord("pruning set to 'custom' in app.toml file, archival node must not use this option", "set pruning to nothing")
+			fatalRecord(appTomlSetting("pruning"), "pruning set to 'custom' in app.toml file, archival node must not use this option", "set pruning to nothing")
 		}
 	default:
 		msg := fmt.Sprintf("invalid pruning option '%s' in app.toml file", app.Pruning)
 		if isArchivalNode {
-			fatalRecord(msg, "set pruning to nothing")
+			fatalRecord(appTomlSetting("pruning"), msg, "set pruning to nothing")
 		} else {
-			fatalRecord(msg, fmt.Sprintf("set pruning to custom %d/10", recommendPruningCustomKeepRecent))
+			fatalRecord(appTomlSetting("pruning"), msg, fmt.Sprintf("set pruning to custom %d/10", recommendPruningCustomKeepRecent))
 		}
-		exitWithErrorMsgf("ERR: invalid pruning option in app.toml file: %s\n", appTomlFilePath, app.Pruning)
+		exitWithErrorMsgf("ERR: invalid pruning option in app.toml file %s: %s\n", appTomlFilePath, app.Pruning)
 		return nil
 	}
 
 	if isSnapshotNode {
 		if app.Pruning != constants.PruningCustom || app.PruningKeepRecent != "100" || app.PruningInterval != "10" {
 			warnRecord(
+				appTomlSetting("pruning"),
 				"snapshot node should use pruning custom 100/10 in app.toml file",
 				"set pruning to 'custom' 100/10",
 			)
@@ -207,12 +253,13 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 			}
 
 			if pruningKeepRecent > 500_000 {
-				warnRecord("pruning-keep-recent is too high in app.toml file", "")
+				warnRecord(appTomlSetting("pruning-keep-recent"), "pruning-keep-recent is too high in app.toml file", "")
 			} else if pruningKeepRecent < 2 {
-				fatalRecord("pruning-keep-recent is too low in app.toml file", "")
+				fatalRecord(appTomlSetting("pruning-keep-recent"), "pruning-keep-recent is too low in app.toml file", "")
 			}
 		} else {
 			fatalRecord(
+				appTomlSetting("pruning-keep-recent"),
 				"pruning-keep-recent is empty in app.toml file",
 				fmt.Sprintf("set pruning-keep-recent to %d", recommendPruningCustomKeepRecent),
 			)
@@ -226,26 +273,27 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 			}
 
 			if pruningInterval > 10_000 {
-				warnRecord("pruning-interval is too high in app.toml file", "")
+				warnRecord(appTomlSetting("pruning-interval"), "pruning-interval is too high in app.toml file", "")
 			} else if pruningInterval < 10 {
-				fatalRecord("pruning-interval is too low in app.toml file", "")
+				fatalRecord(appTomlSetting("pruning-interval"), "pruning-interval is too low in app.toml file", "")
 			}
 		} else {
-			fatalRecord("pruning-interval is empty in app.toml file", "set pruning-interval to 10")
+			fatalRecord(appTomlSetting("pruning-interval"), "pruning-interval is empty in app.toml file", "set pruning-interval to 10")
 		}
 	}
 
 	if app.HaltHeight > 0 {
-		warnRecord(fmt.Sprintf("halt-height is set to %d in app.toml file", app.HaltHeight), "unset halt-height unless on purpose")
+		warnRecord(appTomlSetting("halt-height"), fmt.Sprintf("halt-height is set to %d in app.toml file", app.HaltHeight), "unset halt-height unless on purpose")
 	}
 
 	if app.HaltTime > 0 {
-		warnRecord(fmt.Sprintf("halt-time is set to %d in app.toml file", app.HaltTime), "unset halt-time unless on purpose")
+		warnRecord(appTomlSetting("halt-time"), fmt.Sprintf("halt-time is set to %d in app.toml file", app.HaltTime), "unset halt-time unless on purpose")
 	}
 
 	if app.Pruning == constants.PruningDefault {
 		if app.MinRetainsBlock < 362880 {
 			warnRecord(
+				appTomlSetting("min-retain-blocks"),
 				"min-retain-blocks should be set to 362880 if pruning \"default\" in app.toml file",
 				"set min-retain-blocks to 362880",
 			)
@@ -253,6 +301,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	} else if app.Pruning == constants.PruningEverything {
 		if app.MinRetainsBlock < 2 {
 			warnRecord(
+				appTomlSetting("min-retain-blocks"),
 				"min-retain-blocks should be set to 2 if pruning \"everything\" in app.toml file",
 				"set min-retain-blocks to 2",
 			)
@@ -265,6 +314,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 		}
 		if uint64(app.MinRetainsBlock) < pruningKeepRecent {
 			warnRecord(
+				appTomlSetting("min-retain-blocks"),
 				fmt.Sprintf("min-retain-blocks should be equals to pruning-keep-recent (%s) in app.toml file", app.PruningKeepRecent),
 				fmt.Sprintf("set min-retain-blocks to \"%s\"", app.PruningKeepRecent),
 			)
@@ -272,6 +322,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	} else if app.Pruning == constants.PruningNothing {
 		if app.MinRetainsBlock != 0 {
 			fatalRecord(
+				appTomlSetting("min-retain-blocks"),
 				"min-retain-blocks must be 0 if pruning \"nothing\" (archival node) in app.toml file",
 				"set min-retain-blocks to 0",
 			)
@@ -284,40 +335,41 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	}
 	if app.Api.Enable {
 		if isValidator {
-			warnRecord("api is enabled in app.toml file, validator should disable it", "set enable to false")
+			warnRecord(appTomlSetting("api.enable"), "api is enabled in app.toml file, validator should disable it", "set enable to false")
 		}
 
 		if !app.Api.Swagger {
 			if isRpc {
-				warnRecord("rpc node should enable swagger", "set swagger to true")
+				warnRecord(appTomlSetting("api.swagger"), "rpc node should enable swagger", "set swagger to true")
 			} else if isArchivalNode {
-				warnRecord("archival node should enable swagger", "set swagger to true")
+				warnRecord(appTomlSetting("api.swagger"), "archival node should enable swagger", "set swagger to true")
 			}
 		}
 	} else {
 		if isRpc {
-			fatalRecord("api is disabled in app.toml file, rpc node should enable it", "set enable to true")
+			fatalRecord(appTomlSetting("api.enable"), "api is disabled in app.toml file, rpc node should enable it", "set enable to true")
 		} else if isArchivalNode {
-			warnRecord("api is disabled in app.toml file, archival node should enable it", "set enable to true")
+			warnRecord(appTomlSetting("api.enable"), "api is disabled in app.toml file, archival node should enable it", "set enable to true")
 		}
 	}
 
 	if app.JsonRpc != nil {
 		if app.JsonRpc.Enable {
 			if isValidator {
-				warnRecord("json-rpc is enabled in app.toml file, validator should disable it", "set enable to false")
+				warnRecord(appTomlSetting("json-rpc.enable"), "json-rpc is enabled in app.toml file, validator should disable it", "set enable to false")
 			}
 		} else {
 			if isRpc {
-				fatalRecord("json-rpc is disabled in app.toml file, rpc node should enable it", "set enable to true")
+				fatalRecord(appTomlSetting("json-rpc.enable"), "json-rpc is disabled in app.toml file, rpc node should enable it", "set enable to true")
 			} else if isArchivalNode {
-				warnRecord("json-rpc is disabled in app.toml file, archival node should enable it", "set enable to true")
+				warnRecord(appTomlSetting("json-rpc.enable"), "json-rpc is disabled in app.toml file, archival node should enable it", "set enable to true")
 			}
 		}
 
 		if app.JsonRpc.EnableIndexer {
 			if isValidator {
 				warnRecord(
+					appTomlSetting("json-rpc.enable-indexer"),
 					"json-rpc custom EVM-indexer is enabled in app.toml file, validator should disable it",
 					"set enable-indexer to false",
 				)
@@ -325,11 +377,13 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 		} else {
 			if isRpc {
 				fatalRecord(
+					appTomlSetting("json-rpc.enable-indexer"),
 					"json-rpc custom EVM-indexer is disabled in app.toml file, rpc node should enable it",
 					"set enable-indexer to true",
 				)
 			} else if isArchivalNode {
 				warnRecord(
+					appTomlSetting("json-rpc.enable-indexer"),
 					"json-rpc custom EVM-indexer is disabled in app.toml file, archival node should enable it",
 					"set enable-indexer to true",
 				)
@@ -344,11 +398,13 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	if app.StateSync.SnapshotInterval == 0 {
 		if isRpc {
 			warnRecord(
+				appTomlSetting("state-sync.snapshot-interval"),
 				"snapshot-interval is 0 (disable snapshot) in app.toml file, RPC nodes should set this",
 				"set snapshot-interval to 2000",
 			)
 		} else if isSnapshotNode {
 			fatalRecord(
+				appTomlSetting("state-sync.snapshot-interval"),
 				"snapshot-interval is 0 (disable snapshot) in app.toml file, snapshot nodes must set this",
 				"set snapshot-interval to 2000",
 			)
@@ -356,11 +412,13 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	} else {
 		if isValidator {
 			warnRecord(
+				appTomlSetting("state-sync.snapshot-interval"),
 				"snapshot-interval is set in app.toml file, validator should not set this",
 				"set snapshot-interval to 0 to disable snapshot",
 			)
 		} else if app.StateSync.SnapshotInterval < 1000 {
 			warnRecord(
+				appTomlSetting("state-sync.snapshot-interval"),
 				"snapshot-interval is too low in app.toml file",
 				"set snapshot-interval to 2000",
 			)
@@ -368,11 +426,13 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	}
 	if app.StateSync.SnapshotKeepRecent == 0 {
 		fatalRecord(
+			appTomlSetting("state-sync.snapshot-keep-recent"),
 			"snapshot-keep-recent is 0 in app.toml file, means keep all, unset it",
 			"set snapshot-keep-recent to 2",
 		)
 	} else if app.StateSync.SnapshotKeepRecent > 2 {
 		warnRecord(
+			appTomlSetting("state-sync.snapshot-keep-recent"),
 			"snapshot-keep-recent is too high in app.toml file, wasting disk space",
 			"set snapshot-keep-recent to 2",
 		)
@@ -384,7 +444,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	}
 	if app.Grpc.Enable {
 		if isValidator {
-			warnRecord("grpc is enabled in app.toml file, validator should disable it", "set [grpc] enable to false")
+			warnRecord(appTomlSetting("grpc.enable"), "grpc is enabled in app.toml file, validator should disable it", "set [grpc] enable to false")
 		}
 	} else {
 		if isValidator {
@@ -393,6 +453,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 			// no problem
 		} else {
 			fatalRecord(
+				appTomlSetting("grpc.enable"),
 				"grpc is disabled in app.toml file, non-validator node should enable it",
 				"set [grpc] enable to true",
 			)
@@ -407,6 +468,7 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 	}
 	if maxSendMsgSize < suggestedMaxSendMsgSizeBytes {
 		warnRecord(
+			appTomlSetting("grpc.max-send-msg-size"),
 			"max-send-msg-size is too low in app.toml file",
 			fmt.Sprintf("set max-send-msg-size to %d (%d MB)", suggestedMaxSendMsgSizeBytes, suggestedMaxSendMsgSizeMb),
 		)
@@ -415,12 +477,14 @@ func checkHomeConfigAppToml(configPath string, nodeType types.NodeType) *types.A
 		if app.Grpc.Enable {
 			if maxSendMsgSize > suggestedMaxSendMsgSizeBytes*5 {
 				warnRecord(
+					appTomlSetting("grpc.max-send-msg-size"),
 					"max-send-msg-size is too high in app.toml file",
 					fmt.Sprintf("set max-send-msg-size to %d (%d MB)", suggestedMaxSendMsgSizeBytes, suggestedMaxSendMsgSizeMb),
 				)
 			}
 			if strings.HasSuffix(app.Grpc.Address, ":9090") {
 				warnRecord(
+					appTomlSetting("grpc.address"),
 					"GRPC port should not be the default one (9090) on RPC and Archival node",
 					"set [grpc] address to a custom port",
 				)
@@ -447,23 +511,27 @@ func checkHomeConfigClientToml(configPath string) {
 		return
 	}
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("client.toml", clientTomlFilePath, "permission"))
 	if filePerm.Other.AnyPermission() {
-		fatalRecord("client.toml file is accessible by others", "chmod 600 "+clientTomlFilePath)
+		fatalRecord(fileTarget("client.toml", clientTomlFilePath, "permission"), "client.toml file is accessible by others", "chmod 600 "+clientTomlFilePath)
 	}
 	if filePerm.Group.AnyPermission() {
-		fatalRecord("client.toml file is accessible by group", "chmod 600 "+clientTomlFilePath)
+		fatalRecord(fileTarget("client.toml", clientTomlFilePath, "permission"), "client.toml file is accessible by group", "chmod 600 "+clientTomlFilePath)
 	}
 	if !filePerm.User.Read {
-		fatalRecord("client.toml file is not readable by user", "chmod 600 "+clientTomlFilePath)
+		fatalRecord(fileTarget("client.toml", clientTomlFilePath, "permission"), "client.toml file is not readable by user", "chmod 600 "+clientTomlFilePath)
 	}
 	if !filePerm.User.Write {
-		fatalRecord("client.toml file is not writable by user", "chmod 600 "+clientTomlFilePath)
+		fatalRecord(fileTarget("client.toml", clientTomlFilePath, "permission"), "client.toml file is not writable by user", "chmod 600 "+clientTomlFilePath)
 	}
 }
 
 func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *types.ConfigToml {
 	isValidator := nodeType == types.ValidatorNode
 	configTomlFilePath := path.Join(configPath, "config.toml")
+	configTomlSetting := func(key string) recordTarget {
+		return settingTarget("config.toml", configTomlFilePath, key)
+	}
 	perm, exists, isDir, err := utils.FileInfo(configTomlFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to check config.toml file at %s: %v\n", configTomlFilePath, err)
@@ -478,17 +546,18 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 		return nil
 	}
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("config.toml", configTomlFilePath, "permission"))
 	if filePerm.Other.Write {
-		fatalRecord("config.toml file is writable by others", "chmod 644 "+configTomlFilePath)
+		fatalRecord(fileTarget("config.toml", configTomlFilePath, "permission"), "config.toml file is writable by others", "chmod 644 "+configTomlFilePath)
 	}
 	if filePerm.Group.Write {
-		fatalRecord("config.toml file is writable by group", "chmod 644 "+configTomlFilePath)
+		fatalRecord(fileTarget("config.toml", configTomlFilePath, "permission"), "config.toml file is writable by group", "chmod 644 "+configTomlFilePath)
 	}
 	if !filePerm.User.Read {
-		fatalRecord("config.toml file is not readable by user", "chmod 644 "+configTomlFilePath)
+		fatalRecord(fileTarget("config.toml", configTomlFilePath, "permission"), "config.toml file is not readable by user", "chmod 644 "+configTomlFilePath)
 	}
 	if !filePerm.User.Write {
-		fatalRecord("config.toml file is not writable by user", "chmod 644 "+configTomlFilePath)
+		fatalRecord(fileTarget("config.toml", configTomlFilePath, "permission"), "config.toml file is not writable by user", "chmod 644 "+configTomlFilePath)
 	}
 
 	bz, err := os.ReadFile(configTomlFilePath)
@@ -504,8 +573,22 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 		return nil
 	}
 
+	evaluatedRule(
+		configTomlSetting("moniker"),
+		configTomlSetting("p2p.seeds"),
+		configTomlSetting("p2p.laddr"),
+		configTomlSetting("p2p.persistent_peers"),
+		configTomlSetting("p2p.max_num_inbound_peers"),
+		configTomlSetting("p2p.max_num_outbound_peers"),
+		configTomlSetting("p2p.seed_mode"),
+		configTomlSetting("statesync.enable"),
+		configTomlSetting("consensus.double_sign_check_height"),
+		configTomlSetting("consensus.skip_timeout_commit"),
+		configTomlSetting("tx_index.indexer"),
+	)
+
 	if config.Moniker == "" {
-		fatalRecord("moniker is empty in config.toml file", "set moniker to a unique name")
+		fatalRecord(configTomlSetting("moniker"), "moniker is empty in config.toml file", "set moniker to a unique name")
 	}
 
 	if config.P2P == nil {
@@ -513,30 +596,26 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 		return nil
 	}
 	if config.P2P.Seeds == "" {
-		warnRecord("seeds is empty in config.toml file", "set seeds to seed nodes")
-	} else if !isValidPeer(config.P2P.Seeds) {
-		warnRecord("invalid seeds format in config.toml file", "correct the format of seeds")
+		warnRecord(configTomlSetting("p2p.seeds"), "seeds is empty in config.toml file", "set seeds to seed nodes")
 	}
 	if strings.HasSuffix(config.P2P.Laddr, ":26656") {
 		if isValidator {
-			warnRecord("P2P port should not be the default one (26656) on validator node", "set p2p laddr to a custom port")
+			warnRecord(configTomlSetting("p2p.laddr"), "P2P port should not be the default one (26656) on validator node", "set p2p laddr to a custom port")
 		} else {
-			warnRecord("P2P port should not be the default one (26656)", "set p2p laddr to a custom port")
+			warnRecord(configTomlSetting("p2p.laddr"), "P2P port should not be the default one (26656)", "set p2p laddr to a custom port")
 		}
 	}
 	if config.P2P.PersistentPeers == "" {
-		warnRecord("persistent_peers is empty in config.toml file", "set persistent_peers to persistent peer nodes")
-	} else if !isValidPeer(config.P2P.PersistentPeers) {
-		warnRecord("invalid persistent_peers format in config.toml file", "correct the format of persistent_peers")
+		warnRecord(configTomlSetting("p2p.persistent_peers"), "persistent_peers is empty in config.toml file", "set persistent_peers to persistent peer nodes")
 	}
 	if config.P2P.MaxNumInboundPeers < 60 {
-		warnRecord("max_num_inbound_peers is too low in config.toml file", "increase max_num_inbound_peers to 120")
+		warnRecord(configTomlSetting("p2p.max_num_inbound_peers"), "max_num_inbound_peers is too low in config.toml file", "increase max_num_inbound_peers to 120")
 	}
 	if config.P2P.MaxNumOutboundPeers <= 30 {
-		warnRecord("max_num_outbound_peers is too low in config.toml file", "increase max_num_outbound_peers to 60")
+		warnRecord(configTomlSetting("p2p.max_num_outbound_peers"), "max_num_outbound_peers is too low in config.toml file", "increase max_num_outbound_peers to 60")
 	}
 	if config.P2P.SeedMode {
-		warnRecord("seed_mode is enabled in config.toml file", "disable seed_mode if not on purpose")
+		warnRecord(configTomlSetting("p2p.seed_mode"), "seed_mode is enabled in config.toml file", "disable seed_mode if not on purpose")
 	}
 
 	if config.StateSync == nil {
@@ -544,7 +623,7 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 		return nil
 	}
 	if config.StateSync.Enable {
-		warnRecord("statesync is enabled in config.toml file", "disable state sync in section [statesync]")
+		warnRecord(configTomlSetting("statesync.enable"), "statesync is enabled in config.toml file", "disable state sync in section [statesync]")
 	}
 
 	if config.Consensus == nil {
@@ -555,11 +634,13 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 		if isValidator {
 			if config.Consensus.DoubleSignCheckHeight > constants.MaxDoubleSignCheckHeight {
 				warnRecord(
+					configTomlSetting("consensus.double_sign_check_height"),
 					fmt.Sprintf("double_sign_check_height %d is too high in config.toml file, can lower uptime", config.Consensus.DoubleSignCheckHeight),
 					fmt.Sprintf("set double_sign_check_height to %d", constants.RecommendDoubleSignCheckHeight),
 				)
 			} else if config.Consensus.DoubleSignCheckHeight < constants.MinDoubleSignCheckHeight {
 				warnRecord(
+					configTomlSetting("consensus.double_sign_check_height"),
 					fmt.Sprintf("double_sign_check_height %d is too low in config.toml file", config.Consensus.DoubleSignCheckHeight),
 					fmt.Sprintf("set double_sign_check_height to %d", constants.RecommendDoubleSignCheckHeight),
 				)
@@ -568,6 +649,7 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	} else {
 		if isValidator {
 			fatalRecord(
+				configTomlSetting("consensus.double_sign_check_height"),
 				"double_sign_check_height is not set in config.toml file, validator nodes should set this",
 				fmt.Sprintf("set double_sign_check_height to %d", constants.RecommendDoubleSignCheckHeight),
 			)
@@ -576,11 +658,13 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	if config.Consensus.SkipTimeoutCommit {
 		if isValidator {
 			fatalRecord(
+				configTomlSetting("consensus.skip_timeout_commit"),
 				"skip_timeout_commit is enabled in config.toml file, validator nodes should not use this",
 				"disable skip_timeout_commit",
 			)
 		} else {
 			warnRecord(
+				configTomlSetting("consensus.skip_timeout_commit"),
 				"skip_timeout_commit is enabled in config.toml file",
 				"disable skip_timeout_commit",
 			)
@@ -595,11 +679,13 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	case "":
 		if isValidator {
 			fatalRecord(
+				configTomlSetting("tx_index.indexer"),
 				"indexer is empty in [tx_index] section of config.toml file, validator nodes should set this to \"null\"",
 				"set indexer to \"null\"",
 			)
 		} else {
 			warnRecord(
+				configTomlSetting("tx_index.indexer"),
 				"indexer is empty in [tx_index] section of config.toml file, non-validator nodes should set this to \"kv\"",
 				"set indexer to \"kv\"",
 			)
@@ -607,6 +693,7 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	case "kv":
 		if isValidator {
 			warnRecord(
+				configTomlSetting("tx_index.indexer"),
 				"indexer is set to \"kv\" in [tx_index] section of config.toml file, validator nodes should set this to \"null\"",
 				"set indexer to \"null\"",
 			)
@@ -614,6 +701,7 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	case "null":
 		if !isValidator {
 			fatalRecord(
+				configTomlSetting("tx_index.indexer"),
 				"indexer is set to \"null\" (disable indexer) in [tx_index] section of config.toml file, non-validator nodes should set this to \"kv\"",
 				"set indexer to \"kv\"",
 			)
@@ -621,11 +709,13 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	default:
 		if isValidator {
 			fatalRecord(
+				configTomlSetting("tx_index.indexer"),
 				fmt.Sprintf("invalid indexer option \"%s\" in [tx_index] section of config.toml file", config.TxIndex.Indexer),
 				"set indexer to \"null\"",
 			)
 		} else {
 			fatalRecord(
+				configTomlSetting("tx_index.indexer"),
 				fmt.Sprintf("invalid indexer option \"%s\" in [tx_index] section of config.toml file", config.TxIndex.Indexer),
 				"set indexer to \"kv\"",
 			)
@@ -635,63 +725,74 @@ func checkHomeConfigConfigToml(configPath string, nodeType types.NodeType) *type
 	return &config
 }
 
-func checkHomeConfigGenesisJson(configPath string) {
+// checkHomeConfigGenesisJson checks genesis.json and returns the parsed genesis.
+func checkHomeConfigGenesisJson(configPath string) *types.Genesis {
 	genesisJsonFilePath := path.Join(configPath, "genesis.json")
 	perm, exists, isDir, err := utils.FileInfo(genesisJsonFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to check genesis.json file at %s: %v\n", genesisJsonFilePath, err)
-		return
+		return nil
 	}
 	if !exists {
 		exitWithErrorMsgf("ERR: genesis.json file does not exist: %s\n", genesisJsonFilePath)
-		return
+		return nil
 	}
 	if isDir {
 		exitWithErrorMsgf("ERR: genesis.json is a directory, it should be a file: %s\n", genesisJsonFilePath)
-		return
+		return nil
 	}
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("genesis.json", genesisJsonFilePath, "permission"))
 	if filePerm.Other.Write {
-		fatalRecord("genesis.json file is writable by others", "chmod 644 "+genesisJsonFilePath)
+		fatalRecord(fileTarget("genesis.json", genesisJsonFilePath, "permission"), "genesis.json file is writable by others", "chmod 644 "+genesisJsonFilePath)
 	}
 	if filePerm.Group.Write {
-		fatalRecord("genesis.json file is writable by group", "chmod 644 "+genesisJsonFilePath)
+		fatalRecord(fileTarget("genesis.json", genesisJsonFilePath, "permission"), "genesis.json file is writable by group", "chmod 644 "+genesisJsonFilePath)
 	}
 	if !filePerm.User.Read {
-		fatalRecord("genesis.json file is not readable by user", "chmod 644 "+genesisJsonFilePath)
+		fatalRecord(fileTarget("genesis.json", genesisJsonFilePath, "permission"), "genesis.json file is not readable by user", "chmod 644 "+genesisJsonFilePath)
 	}
 	if !filePerm.User.Write {
-		fatalRecord("genesis.json file is not writable by user", "chmod 644 "+genesisJsonFilePath)
+		fatalRecord(fileTarget("genesis.json", genesisJsonFilePath, "permission"), "genesis.json file is not writable by user", "chmod 644 "+genesisJsonFilePath)
+	}
+
+	genesis, err := utils.ReadGenesis(genesisJsonFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read genesis.json file at %s: %v\n", genesisJsonFilePath, err)
+		return nil
 	}
+	return genesis
 }
 
-func checkHomeConfigNodeKeyJson(configPath string) {
+// checkHomeConfigNodeKeyJson checks node_key.json and returns the node ID derived from the key.
+func checkHomeConfigNodeKeyJson(configPath string) string {
 	nodeKeyJsonFilePath := path.Join(configPath, "node_key.json")
 	perm, exists, isDir, err := utils.FileInfo(nodeKeyJsonFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to check node_key.json file at %s: %v\n", nodeKeyJsonFilePath, err)
-		return
+		return ""
 	}
 	if !exists {
 		exitWithErrorMsgf("ERR: node_key.json file does not exist: %s\n", nodeKeyJsonFilePath)
-		return
+		return ""
 	}
 	if isDir {
 		exitWithErrorMsgf("ERR: node_key.json is a directory, it should be a file: %s\n", nodeKeyJsonFilePath)
-		return
+		return ""
 	}
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("node_key.json", nodeKeyJsonFilePath, "permission"))
 	if filePerm.Other.AnyPermission() {
-		fatalRecord("node_key.json file is accessible by others", "chmod 600 "+nodeKeyJsonFilePath)
+		fatalRecord(fileTarget("node_key.json", nodeKeyJsonFilePath, "permission"), "node_key.json file is accessible by others", "chmod 600 "+nodeKeyJsonFilePath)
 	}
 	if filePerm.Group.AnyPermission() {
-		fatalRecord("node_key.json file is accessible by group", "chmod 600 "+nodeKeyJsonFilePath)
+		fatalRecord(fileTarget("node_key.json", nodeKeyJsonFilePath, "permission"), "node_key.json file is accessible by group", "chmod 600 "+nodeKeyJsonFilePath)
 	}
 	if !filePerm.User.Read {
-		fatalRecord("node_key.json file is not readable by user", "chmod 600 "+nodeKeyJsonFilePath)
+		fatalRecord(fileTarget("node_key.json", nodeKeyJsonFilePath, "permission"), "node_key.json file is not readable by user", "chmod 600 "+nodeKeyJsonFilePath)
 	}
 	if !filePerm.User.Write {
-		fatalRecord("node_key.json file is not writable by user", "chmod 600 "+nodeKeyJsonFilePath)
+		fatalRecord(fileTarget("node_key.json", nodeKeyJsonFilePath, "permission"), "node_key.json file is not writable by user", "chmod 600 "+nodeKeyJsonFilePath)
 	}
 
 	type nodeKeyPrivKey struct {
@@ -705,34 +806,98 @@ func checkHomeConfigNodeKeyJson(configPath string) {
 	bz, err := os.ReadFile(nodeKeyJsonFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to read node_key.json file at %s: %v\n", nodeKeyJsonFilePath, err)
-		return
+		return ""
 	}
 
 	if len(bz) == 0 {
 		exitWithErrorMsgf("ERR: node_key.json file is empty: %s\n", nodeKeyJsonFilePath)
-		return
+		return ""
 	}
 
 	var nk nodeKey
 	err = json.Unmarshal(bz, &nk)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to unmarshal node_key.json file at %s: %v\n", nodeKeyJsonFilePath, err)
-		return
+		return ""
 	}
 
 	if nk.PrivKey == nil {
 		exitWithErrorMsgf("ERR: priv_key is missing in node_key.json file at %s\n", nodeKeyJsonFilePath)
-		return
+		return ""
 	}
 
 	if len(nk.PrivKey.Type) == 0 {
 		exitWithErrorMsgf("ERR: type is missing in priv_key in node_key.json file at %s\n", nodeKeyJsonFilePath)
-		return
+		return ""
 	}
 
 	if len(nk.PrivKey.Value) == 0 {
 		exitWithErrorMsgf("ERR: value is missing in priv_key in node_key.json file at %s\n", nodeKeyJsonFilePath)
-		return
+		return ""
+	}
+
+	evaluatedRule(fileTarget("node_key.json", nodeKeyJsonFilePath, "node-key"))
+	privKey, err := base64.StdEncoding.DecodeString(nk.PrivKey.Value)
+	if err != nil {
+		fatalRecord(fileTarget("node_key.json", nodeKeyJsonFilePath, "node-key"), "value of priv_key in node_key.json is not valid base64", "restore node_key.json from backup, or remove it to generate a new one")
+		return ""
+	}
+	nodeId, err := utils.NodeId(nk.PrivKey.Type, privKey)
+	if err != nil {
+		fatalRecord(fileTarget("node_key.json", nodeKeyJsonFilePath, "node-key"), fmt.Sprintf("invalid priv_key in node_key.json: %v", err), "restore node_key.json from backup, or remove it to generate a new one")
+		return ""
+	}
+
+	return nodeId
+}
+
+// checkHomeConfigSelfPeering reports the node ID of this node in its own peer settings,
+// which happens when config.toml is copied from another node.
+func checkHomeConfigSelfPeering(configPath string, configToml *types.ConfigToml, nodeId string) {
+	configTomlFilePath := path.Join(configPath, "config.toml")
+	for _, setting := range []struct {
+		key   string
+		value string
+	}{
+		{key: "seeds", value: configToml.P2P.Seeds},
+		{key: "persistent_peers", value: configToml.P2P.PersistentPeers},
+		{key: "unconditional_peer_ids", value: configToml.P2P.UnconditionalPeerIds},
+		{key: "private_peer_ids", value: configToml.P2P.PrivatePeerIds},
+	} {
+		evaluatedRule(settingTarget("config.toml", configTomlFilePath, "p2p."+setting.key))
+		for _, entry := range strings.Split(setting.value, ",") {
+			peerId, _, _ := strings.Cut(strings.TrimSpace(entry), "@")
+			if !strings.EqualFold(peerId, nodeId) {
+				continue
+			}
+			warnRecord(
+				settingTarget("config.toml", configTomlFilePath, "p2p."+setting.key),
+				fmt.Sprintf("%s in config.toml contains ID %s of this node itself, config was probably copied from another node", setting.key, nodeId),
+				fmt.Sprintf("remove %s from %s", strings.TrimSpace(entry), setting.key),
+			)
+		}
+	}
+}
+
+// checkDuplicateNodeIds reports homes sharing the same node ID, peers reject the second connection of the same ID.
+func checkDuplicateNodeIds(homes []string, nodeIds []string) {
+	firstHomeOfId := make(map[string]string)
+	for i, nodeId := range nodeIds {
+		if nodeId == "" {
+			continue
+		}
+		evaluatedRule(fileTarget("node_key.json", path.Join(homes[i], "config", "node_key.json"), "duplicate-node-id"))
+		firstHome, duplicated := firstHomeOfId[nodeId]
+		if !duplicated {
+			firstHomeOfId[nodeId] = homes[i]
+			continue
+		}
+		nodeKeyJsonFilePath := path.Join(homes[i], "config", "node_key.json")
+		fatalRecord(
+			fileTarget("node_key.json", nodeKeyJsonFilePath, "duplicate-node-id"),
+			fmt.Sprintf("node ID %s of %s is the same as of %s, node_key.json was copied", nodeId, homes[i], firstHome),
+			fmt.Sprintf("stop the node, remove %s and start again to generate a new node key", nodeKeyJsonFilePath),
+		)
 	}
 }
 
@@ -752,17 +917,18 @@ func checkHomeConfigPrivValidatorKeyJson(configPath string) {
 		return
 	}
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "permission"))
 	if filePerm.Other.AnyPermission() {
-		fatalRecord("priv_validator_key.json file is accessible by others", "chmod 600 "+privValidatorJsonFilePath)
+		fatalRecord(fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "permission"), "priv_validator_key.json file is accessible by others", "chmod 600 "+privValidatorJsonFilePath)
 	}
 	if filePerm.Group.AnyPermission() {
-		fatalRecord("priv_validator_key.json file is accessible by group", "chmod 600 "+privValidatorJsonFilePath)
+		fatalRecord(fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "permission"), "priv_validator_key.json file is accessible by group", "chmod 600 "+privValidatorJsonFilePath)
 	}
 	if !filePerm.User.Read {
-		fatalRecord("priv_validator_key.json file is not readable by user", "chmod 600 "+privValidatorJsonFilePath)
+		fatalRecord(fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "permission"), "priv_validator_key.json file is not readable by user", "chmod 600 "+privValidatorJsonFilePath)
 	}
 	if !filePerm.User.Write {
-		fatalRecord("priv_validator_key.json file is not writable by user", "chmod 600 "+privValidatorJsonFilePath)
+		fatalRecord(fileTarget("priv_validator_key.json", privValidatorJsonFilePath, "permission"), "priv_validator_key.json file is not writable by user", "chmod 600 "+privValidatorJsonFilePath)
 	}
 
 	type privKey struct {
@@ -838,11 +1004,16 @@ func checkHomeConfigPrivValidatorKeyJson(configPath string) {
 	}
 }
 
-func checkHomeConfigConfigTomlAndAppToml(nodeType types.NodeType, configToml *types.ConfigToml, appToml *types.AppToml) {
+func checkHomeConfigConfigTomlAndAppToml(configPath string, nodeType types.NodeType, configToml *types.ConfigToml, appToml *types.AppToml) {
 	if configToml == nil || appToml == nil {
 		panic("configToml or appToml is nil")
 	}
 
+	appTomlFilePath := path.Join(configPath, "app.toml")
+	appTomlSetting := func(key string) recordTarget {
+		return settingTarget("app.toml", appTomlFilePath, key)
+	}
+
 	isValidator := nodeType == types.ValidatorNode
 
 	if isValidator {
@@ -856,6 +1027,7 @@ func checkHomeConfigConfigTomlAndAppToml(nodeType types.NodeType, configToml *ty
 
 				if pruningKeepRecent <= uint64(configToml.Consensus.DoubleSignCheckHeight) {
 					warnRecord(
+						appTomlSetting("pruning-keep-recent"),
 						fmt.Sprintf(
 							"pruning-keep-recent %d should be greater than double_sign_check_height %d in app.toml file",
 							pruningKeepRecent,
@@ -868,6 +1040,7 @@ func checkHomeConfigConfigTomlAndAppToml(nodeType types.NodeType, configToml *ty
 
 			if appToml.MinRetainsBlock <= configToml.Consensus.DoubleSignCheckHeight {
 				warnRecord(
+					appTomlSetting("min-retain-blocks"),
 					fmt.Sprintf(
 						"min-retain-blocks %d should be greater than double_sign_check_height %d in app.toml file",
 						appToml.MinRetainsBlock,
@@ -2,13 +2,15 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/bcdevtools/node-setup-check/types"
 	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pkg/errors"
 	"os"
 	"path"
 )
 
-func checkHomeData(home string, nodeType types.NodeType) {
+func checkHomeData(home string, nodeType types.NodeType, remoteSigner bool) {
 	dataPath := path.Join(home, "data")
 	perm, exists, isDir, err := utils.FileInfo(dataPath)
 	if err != nil {
@@ -25,14 +27,15 @@ func checkHomeData(home string, nodeType types.NodeType) {
 	}
 
 	filePerm := types.FilePermFrom(perm)
+	evaluatedRule(fileTarget("data-dir", dataPath, "permission"))
 	if filePerm.Other.AnyPermission() {
-		fatalRecord("data directory is accessible by others", "chmod 700 "+dataPath)
+		fatalRecord(fileTarget("data-dir", dataPath, "permission"), "data directory is accessible by others", "chmod 700 "+dataPath)
 	}
 	if filePerm.Group.AnyPermission() {
-		fatalRecord("data directory is accessible by group", "chmod 700 "+dataPath)
+		fatalRecord(fileTarget("data-dir", dataPath, "permission"), "data directory is accessible by group", "chmod 700 "+dataPath)
 	}
 	if !filePerm.User.IsFullPermission() {
-		fatalRecord("data directory is not fully accessible by user", "chmod 700 "+dataPath)
+		fatalRecord(fileTarget("data-dir", dataPath, "permission"), "data directory is not fully accessible by user", "chmod 700 "+dataPath)
 	}
 
 	privValidatorStateFilePath := path.Join(dataPath, "priv_validator_state.json")
@@ -49,19 +52,17 @@ func checkHomeData(home string, nodeType types.NodeType) {
 		exitWithErrorMsgf("ERR: priv_validator_state.json is a directory, it should be a file")
 		return
 	}
+	evaluatedRule(fileTarget("priv_validator_state.json", privValidatorStateFilePath, "permission"))
 	if perm != 0o600 {
-		fatalRecord("priv_validator_state.json has invalid permission", "chmod 600 "+privValidatorStateFilePath)
+		fatalRecord(fileTarget("priv_validator_state.json", privValidatorStateFilePath, "permission"), "priv_validator_state.json has invalid permission", "chmod 600 "+privValidatorStateFilePath)
 	}
 
-	type privateValidatorState struct {
-		Height    string `json:"height"`
-		Round     int    `json:"round"`
-		Step      int    `json:"step"`
-		Signature string `json:"signature"`
-		SignBytes string `json:"signbytes"`
+	if remoteSigner {
+		// signing state is kept by the remote signer, the one of the node is not used
+		return
 	}
 
-	var pvs privateValidatorState
+	var pvs types.PrivValidatorState
 	bz, err := os.ReadFile(privValidatorStateFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to read priv_validator_state.json file: %v\n", err)
@@ -74,10 +75,13 @@ func checkHomeData(home string, nodeType types.NodeType) {
 		return
 	}
 
-	if pvs.Height == "0" && pvs.Round == 0 && pvs.Step == 0 && pvs.Signature == "" && pvs.SignBytes == "" {
+	if nodeType == types.ValidatorNode {
+		evaluatedRule(fileTarget("priv_validator_state.json", privValidatorStateFilePath, "empty"))
+	}
+	if pvs.IsEmpty() {
 		// empty
 		if nodeType == types.ValidatorNode {
-			fatalRecord("priv_validator_state.json is empty", "can be ignored if this is a fresh validator node")
+			fatalRecord(fileTarget("priv_validator_state.json", privValidatorStateFilePath, "empty"), "priv_validator_state.json is empty", "can be ignored if this is a fresh validator node")
 		}
 	} else {
 		if nodeType == types.ValidatorNode {
@@ -115,4 +119,95 @@ func checkHomeData(home string, nodeType types.NodeType) {
 			return
 		}
 	}
+	if nodeType == types.ValidatorNode {
+		checkHomeDataPrivValidatorStateAgainstBlockStore(dataPath, privValidatorStateFilePath, pvs)
+	}
+}
+
+// maxPrivValidatorStateAheadOfBlockStore is the number of blocks that signing state can be ahead of block store,
+// because the node signs the next block before it is committed into the block store.
+const maxPrivValidatorStateAheadOfBlockStore = 10
+
+// checkHomeDataPrivValidatorStateAgainstBlockStore compares the last signed height with the height of the block store.
+func checkHomeDataPrivValidatorStateAgainstBlockStore(dataPath, privValidatorStateFilePath string, pvs types.PrivValidatorState) {
+	blockStorePath := path.Join(dataPath, "blockstore.db")
+	_, exists, isDir, err := utils.FileInfo(blockStorePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check blockstore.db directory at %s: %v\n", blockStorePath, err)
+		return
+	}
+	if !exists || !isDir {
+		return
+	}
+
+	target := fileTarget("priv_validator_state.json", privValidatorStateFilePath, "block-store-height")
+
+	backend, err := utils.DetectDbBackend(blockStorePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to detect database backend of %s: %v\n", blockStorePath, err)
+		return
+	}
+	if backend == "" {
+		warnRecord(target, fmt.Sprintf("unable to recognize database backend of %s, height of priv_validator_state.json was not compared with the block store", blockStorePath), "compare manually")
+		return
+	}
+	if backend != utils.DbBackendGoLevelDb && backend != utils.DbBackendPebble {
+		warnRecord(target, fmt.Sprintf("reading %s block store is not supported, height of priv_validator_state.json was not compared with the block store", backend), "compare manually")
+		return
+	}
+
+	blockStoreState, err := utils.ReadBlockStoreState(blockStorePath, backend)
+	if errors.Is(err, utils.ErrDbLocked) {
+		// node is running, that is not a finding
+		printfStdErr("NOTICE: %s is in use by the running node, height of priv_validator_state.json was not compared with the block store\n", blockStorePath)
+		return
+	}
+	if errors.Is(err, os.ErrPermission) {
+		warnRecord(
+			target,
+			fmt.Sprintf("permission denied to read block store %s, height of priv_validator_state.json was not compared with the block store", blockStorePath),
+			"re-run as the user of the validator service, e.g. sudo -u <service user> nodesc check ...",
+		)
+		return
+	}
+	if err != nil {
+		warnRecord(
+			target,
+			fmt.Sprintf("failed to read block store height from %s (%s), height of priv_validator_state.json was not compared with the block store: %v", blockStorePath, backend, err),
+			"compare manually",
+		)
+		return
+	}
+	evaluatedRule(target)
+	if blockStoreState.Height == 0 {
+		// fresh node
+		return
+	}
+
+	stateHeight, err := pvs.HeightInt()
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to parse height of priv_validator_state.json: %v\n", err)
+		return
+	}
+
+	if stateHeight > 0 && stateHeight < blockStoreState.Height {
+		// empty state is reported by the empty check
+		warnRecord(
+			target,
+			fmt.Sprintf(
+				"priv_validator_state.json height %d is behind the block store height %d, expected when the node was block-synced or state-synced, or the validator is not in the active set, otherwise signing state seems to be reset, risk of double sign",
+				stateHeight, blockStoreState.Height,
+			),
+			"if this validator was signing, restore its latest priv_validator_state.json before starting the node",
+		)
+	} else if stateHeight > blockStoreState.Height+maxPrivValidatorStateAheadOfBlockStore {
+		fatalRecord(
+			target,
+			fmt.Sprintf(
+				"priv_validator_state.json height %d is far ahead of the block store height %d, signing state seems to be restored or copied from another node",
+				stateHeight, blockStoreState.Height,
+			),
+			"verify the signing state belongs to this chain and validator, node will not sign until reaching that height",
+		)
+	}
 }
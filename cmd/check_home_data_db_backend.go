@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"path"
+	"strings"
+)
+
+// dataStore is a database under the data directory.
+type dataStore struct {
+	name     string
+	isAppDb  bool // uses app-db-backend of app.toml, falls back to db_backend of config.toml
+	optional bool
+}
+
+var dataStores = []dataStore{
+	{name: "application.db", isAppDb: true},
+	{name: "blockstore.db"},
+	{name: "state.db"},
+	{name: "tx_index.db", optional: true},
+	{name: "evidence.db", optional: true},
+	{name: "evmindexer.db", isAppDb: true, optional: true},
+}
+
+// checkHomeDataDbBackend detects the actual backend of each store in the data directory,
+// then compares with the configured backend.
+func checkHomeDataDbBackend(home string, nodeType types.NodeType, configToml *types.ConfigToml, appToml *types.AppToml) {
+	if configToml == nil || appToml == nil {
+		panic("configToml or appToml is nil")
+	}
+
+	dataPath := path.Join(home, "data")
+	configTomlFilePath := path.Join(home, "config", "config.toml")
+	appTomlFilePath := path.Join(home, "config", "app.toml")
+
+	configuredBackend := strings.TrimSpace(configToml.DbBackend)
+	configuredAppBackend := strings.TrimSpace(appToml.AppDbBackend)
+	if configuredAppBackend == "" {
+		configuredAppBackend = configuredBackend
+	}
+
+	checkConfiguredBackend(nodeType, configuredBackend, settingTarget("config.toml", configTomlFilePath, "db_backend"), "db_backend in config.toml")
+	if strings.TrimSpace(appToml.AppDbBackend) != "" {
+		checkConfiguredBackend(nodeType, configuredAppBackend, settingTarget("app.toml", appTomlFilePath, "app-db-backend"), "app-db-backend in app.toml")
+	}
+
+	detectedBackends := make(map[string][]string) // backend => stores
+	for _, store := range dataStores {
+		storePath := path.Join(dataPath, store.name)
+		_, exists, _, err := utils.FileInfo(storePath)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check %s at %s: %v\n", store.name, storePath, err)
+			return
+		}
+		if !exists {
+			continue
+		}
+
+		if !store.optional {
+			evaluatedRule(fileTarget("data-dir", storePath, "db-backend-unknown"))
+		}
+		backend, err := utils.DetectDbBackend(storePath)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to detect database backend of %s: %v\n", storePath, err)
+			return
+		}
+		if backend == "" {
+			if !store.optional {
+				warnRecord(
+					fileTarget("data-dir", storePath, "db-backend-unknown"),
+					fmt.Sprintf("unable to recognize database backend of %s", storePath),
+					"",
+				)
+			}
+			continue
+		}
+
+		detectedBackends[backend] = append(detectedBackends[backend], store.name)
+
+		expectedBackend := configuredBackend
+		configTarget := settingTarget("config.toml", configTomlFilePath, "db_backend")
+		configName := "db_backend in config.toml"
+		if store.isAppDb {
+			expectedBackend = configuredAppBackend
+			if strings.TrimSpace(appToml.AppDbBackend) != "" {
+				configTarget = settingTarget("app.toml", appTomlFilePath, "app-db-backend")
+				configName = "app-db-backend in app.toml"
+			}
+		}
+
+		evaluatedRule(configTarget)
+		if expectedBackend != "" && !isSameDbBackend(expectedBackend, backend) {
+			fatalRecord(
+				configTarget,
+				fmt.Sprintf("%s is %s on disk but %s is %q", store.name, backend, configName, expectedBackend),
+				fmt.Sprintf("set %s to %q, or re-sync the node with the configured backend", configName, backend),
+			)
+		}
+	}
+
+	evaluatedRule(fileTarget("data-dir", dataPath, "db-backend-mixed"))
+	if len(detectedBackends) > 1 {
+		var parts []string
+		for _, backend := range sortedKeys(detectedBackends) {
+			parts = append(parts, fmt.Sprintf("%s (%s)", backend, strings.Join(detectedBackends[backend], ", ")))
+		}
+		warnRecord(
+			fileTarget("data-dir", dataPath, "db-backend-mixed"),
+			fmt.Sprintf("mixed database backends across stores in data directory: %s", strings.Join(parts, "; ")),
+			"use the same backend for all stores",
+		)
+	}
+}
+
+// checkConfiguredBackend reports backends which are unsupported or discouraged for the node type.
+func checkConfiguredBackend(nodeType types.NodeType, backend string, target recordTarget, configName string) {
+	evaluatedRule(target)
+	switch backend {
+	case "":
+		warnRecord(target, fmt.Sprintf("%s is empty", configName), fmt.Sprintf("set %s to %q", configName, utils.DbBackendGoLevelDb))
+	case utils.DbBackendGoLevelDb:
+		if nodeType == types.ArchivalNode {
+			warnRecord(
+				target,
+				fmt.Sprintf("%s is %q, performance degrades when database grows large on archival node", configName, backend),
+				fmt.Sprintf("consider %q for new archival node", utils.DbBackendPebble),
+			)
+		}
+	case utils.DbBackendPebble:
+		// good for all node types
+	case utils.DbBackendRocksDb:
+		if nodeType == types.ValidatorNode {
+			warnRecord(
+				target,
+				fmt.Sprintf("%s is %q, which requires a custom build and is less tested on validator node", configName, backend),
+				fmt.Sprintf("set %s to %q or %q", configName, utils.DbBackendGoLevelDb, utils.DbBackendPebble),
+			)
+		}
+	case utils.DbBackendCLevelDb:
+		warnRecord(
+			target,
+			fmt.Sprintf("%s is %q, which is deprecated and requires cgo", configName, backend),
+			fmt.Sprintf("set %s to %q", configName, utils.DbBackendGoLevelDb),
+		)
+	case utils.DbBackendBoltDb, utils.DbBackendBadger:
+		fatalRecord(
+			target,
+			fmt.Sprintf("%s is %q, which is deprecated and unsupported by recent CometBFT", configName, backend),
+			fmt.Sprintf("set %s to %q or %q and re-sync the node", configName, utils.DbBackendGoLevelDb, utils.DbBackendPebble),
+		)
+	default:
+		fatalRecord(target, fmt.Sprintf("invalid %s %q", configName, backend), fmt.Sprintf("set %s to %q", configName, utils.DbBackendGoLevelDb))
+	}
+}
+
+// isSameDbBackend compares configured backend with the detected one.
+// goleveldb and cleveldb share the same on-disk format.
+func isSameDbBackend(configured, detected string) bool {
+	if configured == utils.DbBackendCLevelDb {
+		configured = utils.DbBackendGoLevelDb
+	}
+	return configured == detected
+}
@@ -23,9 +23,12 @@ func checkHomeKeyringFile(home string, isValidatorNode bool) {
 		return
 	}
 
+	if isValidatorNode {
+		evaluatedRule(fileTarget("keyring-file", keyringFilePath, "missing"))
+	}
 	if !exists {
 		if isValidatorNode {
-			warnRecord(fmt.Sprintf("keyring-file directory is missing on validator node: %s", keyringFilePath), "can be ignored if you are not using keyring-file")
+			warnRecord(fileTarget("keyring-file", keyringFilePath, "missing"), fmt.Sprintf("keyring-file directory is missing on validator node: %s", keyringFilePath), "can be ignored if you are not using keyring-file")
 		}
 		return
 	}
@@ -36,18 +39,26 @@ func checkHomeKeyringFile(home string, isValidatorNode bool) {
 	}
 
 	if !isValidatorNode {
+		evaluatedRule(fileTarget("keyring-file", keyringFilePath, "non-validator"))
 		isEmpty, err := isEmptyDir(keyringFilePath)
 		if err != nil {
 			exitWithErrorMsgf("ERR: failed to check emptiness of keyring-file directory at %s: %v\n", keyringFilePath, err)
 			return
 		}
 		if !isEmpty {
-			warnRecord(fmt.Sprintf("should not store key on non-validator node, found at %s", keyringFilePath), "migrate/backup and remove usage of keyring-file")
+			warnRecord(fileTarget("keyring-file", keyringFilePath, "non-validator"), fmt.Sprintf("should not store key on non-validator node, found at %s", keyringFilePath), "migrate/backup and remove usage of keyring-file")
 		}
 	}
 
+	evaluatedRule(
+		fileTarget("keyring-file", keyringFilePath, "permission"),
+		fileTarget("keyring-file", keyringFilePath, "inner-permission"),
+	)
+	if isValidatorNode {
+		evaluatedRule(fileTarget("keyring-file", keyringFilePath, "keyhash-missing"))
+	}
 	if perm != 0o700 {
-		fatalRecord(fmt.Sprintf("keyring-file directory has invalid permission %s", perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringFilePath))
+		fatalRecord(fileTarget("keyring-file", keyringFilePath, "permission"), fmt.Sprintf("keyring-file directory has invalid permission %s", perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringFilePath))
 	}
 
 	// check file hash
@@ -64,20 +75,21 @@ func checkHomeKeyringFile(home string, isValidatorNode bool) {
 		}
 
 		filePerm := types.FilePermFrom(perm)
+		evaluatedRule(fileTarget("keyring-file", fileHashPath, "keyhash-permission"))
 		if filePerm.Other.AnyPermission() {
-			fatalRecord("keyhash file should not be accessible by others", fmt.Sprintf("chmod 600 %s", fileHashPath))
+			fatalRecord(fileTarget("keyring-file", fileHashPath, "keyhash-permission"), "keyhash file should not be accessible by others", fmt.Sprintf("chmod 600 %s", fileHashPath))
 		}
 		if filePerm.Group.AnyPermission() {
-			fatalRecord("keyhash file should not be accessible by group", fmt.Sprintf("chmod 600 %s", fileHashPath))
+			fatalRecord(fileTarget("keyring-file", fileHashPath, "keyhash-permission"), "keyhash file should not be accessible by group", fmt.Sprintf("chmod 600 %s", fileHashPath))
 		}
 		if !filePerm.User.Read {
-			fatalRecord("keyhash file should be readable by owner", fmt.Sprintf("chmod 600 %s", fileHashPath))
+			fatalRecord(fileTarget("keyring-file", fileHashPath, "keyhash-permission"), "keyhash file should be readable by owner", fmt.Sprintf("chmod 600 %s", fileHashPath))
 		}
 		if !filePerm.User.Write {
-			fatalRecord("keyhash file should be writable by owner", fmt.Sprintf("chmod 600 %s", fileHashPath))
+			fatalRecord(fileTarget("keyring-file", fileHashPath, "keyhash-permission"), "keyhash file should be writable by owner", fmt.Sprintf("chmod 600 %s", fileHashPath))
 		}
 	} else if isValidatorNode {
-		warnRecord(fmt.Sprintf("keyhash file is missing on validator node: %s", fileHashPath), "can be ignored if you are not using keyring-file")
+		warnRecord(fileTarget("keyring-file", fileHashPath, "keyhash-missing"), fmt.Sprintf("keyhash file is missing on validator node: %s", fileHashPath), "can be ignored if you are not using keyring-file")
 	}
 
 	err = filepath.Walk(keyringFilePath, func(path string, info os.FileInfo, err error) error {
@@ -95,9 +107,9 @@ func checkHomeKeyringFile(home string, isValidatorNode bool) {
 		}
 
 		if isDir && perm != 0o700 {
-			fatalRecord(fmt.Sprintf("keyring-file inner directory must have permission 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringFilePath))
+			fatalRecord(fileTarget("keyring-file", path, "inner-permission"), fmt.Sprintf("keyring-file inner directory must have permission 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringFilePath))
 		} else if !isDir && perm != 0o600 && perm != 0o700 {
-			fatalRecord(fmt.Sprintf("keyring-file inner file must have permission 600 or 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 600 %s", keyringFilePath))
+			fatalRecord(fileTarget("keyring-file", path, "inner-permission"), fmt.Sprintf("keyring-file inner file must have permission 600 or 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 600 %s", keyringFilePath))
 		}
 
 		return nil
@@ -125,8 +137,13 @@ func checkHomeKeyringTest(home string, isValidatorNode bool) {
 		return
 	}
 
+	evaluatedRule(
+		fileTarget("keyring-test", keyringTestPath, "permission"),
+		fileTarget("keyring-test", keyringTestPath, "in-use"),
+		fileTarget("keyring-test", keyringTestPath, "inner-permission"),
+	)
 	if perm != 0o700 {
-		fatalRecord(fmt.Sprintf("keyring-test directory has invalid permission %s", perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringTestPath))
+		fatalRecord(fileTarget("keyring-test", keyringTestPath, "permission"), fmt.Sprintf("keyring-test directory has invalid permission %s", perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringTestPath))
 	}
 
 	isEmpty, err := isEmptyDir(keyringTestPath)
@@ -140,7 +157,7 @@ func checkHomeKeyringTest(home string, isValidatorNode bool) {
 			exitWithErrorMsgf("ERR: keyring-test directory is found on validator node: %s ! Migrate/backup and remove usage of keyring-test\n> rm -rf %s", keyringTestPath, keyringTestPath)
 			return
 		}
-		fatalRecord("keyring-test should not be used, found at "+keyringTestPath, "migrate/backup and remove usage of keyring-test")
+		fatalRecord(fileTarget("keyring-test", keyringTestPath, "in-use"), "keyring-test should not be used, found at "+keyringTestPath, "migrate/backup and remove usage of keyring-test")
 	}
 
 	err = filepath.Walk(keyringTestPath, func(path string, info os.FileInfo, err error) error {
@@ -158,9 +175,9 @@ func checkHomeKeyringTest(home string, isValidatorNode bool) {
 		}
 
 		if isDir && perm != 0o700 {
-			fatalRecord(fmt.Sprintf("keyring-test inner directory must have permission 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringTestPath))
+			fatalRecord(fileTarget("keyring-test", path, "inner-permission"), fmt.Sprintf("keyring-test inner directory must have permission 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 700 %s", keyringTestPath))
 		} else if !isDir && perm != 0o600 && perm != 0o700 {
-			fatalRecord(fmt.Sprintf("keyring-test inner file must have permission 600 or 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 600 %s", keyringTestPath))
+			fatalRecord(fileTarget("keyring-test", path, "inner-permission"), fmt.Sprintf("keyring-test inner file must have permission 600 or 700 but %s has invalid permission %s", path, perm.String()), fmt.Sprintf("chmod -R 600 %s", keyringTestPath))
 		}
 
 		return nil
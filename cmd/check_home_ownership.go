@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/sergeymakinen/go-systemdconf/v2"
+	"github.com/sergeymakinen/go-systemdconf/v2/unit"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ownershipExamplesLimit is the number of mismatched files printed as examples.
+const ownershipExamplesLimit = 3
+
+// checkHomeOwnership checks that the home, config, data and keyring trees are owned by the User of the service,
+// names are resolved through passwd and group files under etcRoot.
+func checkHomeOwnership(home string, etcRoot string, serviceFilePath string) {
+	bz, err := os.ReadFile(serviceFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read service file: %v\n", err)
+		return
+	}
+	var sf unit.ServiceFile
+	err = systemdconf.Unmarshal(bz, &sf)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to unmarshal service file: %v\n", err)
+		return
+	}
+
+	serviceUser := strings.TrimSpace(sf.Service.User.String())
+	if serviceUser == "" {
+		// reported by service file check
+		return
+	}
+
+	accounts, err := utils.ReadSystemAccounts(etcRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read system users and groups: %v\n", err)
+		return
+	}
+	evaluatedRule(settingTarget("service", serviceFilePath, "Service.User"))
+	user := accounts.LookupUser(serviceUser)
+	if user == nil {
+		fatalRecord(
+			settingTarget("service", serviceFilePath, "Service.User"),
+			fmt.Sprintf("User %s in [Service] section does not exist in %s", serviceUser, filepath.Join(etcRoot, "passwd")),
+			"sudo adduser --disabled-password "+serviceUser,
+		)
+		return
+	}
+	groupName := accounts.GroupName(user.Gid)
+	chownSuggest := func(filePath string, recursive bool) string {
+		if recursive {
+			return fmt.Sprintf("sudo chown -R %s:%s %s", user.Name, groupName, filePath)
+		}
+		return fmt.Sprintf("sudo chown %s:%s %s", user.Name, groupName, filePath)
+	}
+
+	// home itself, its content is checked per tree
+	uid, gid, err := utils.FileOwner(home)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check ownership of home directory %s: %v\n", home, err)
+		return
+	}
+	evaluatedRule(fileTarget("home", home, "owner"))
+	if uid != user.Uid {
+		fatalRecord(
+			fileTarget("home", home, "owner"),
+			fmt.Sprintf("home directory is owned by %s, not the service User %s", accounts.UserName(uid), user.Name),
+			chownSuggest(home, false),
+		)
+	} else if gid != user.Gid {
+		warnRecord(
+			fileTarget("home", home, "owner"),
+			fmt.Sprintf("home directory group is %s, not the primary group %s of the service User %s", accounts.GroupName(gid), groupName, user.Name),
+			chownSuggest(home, false),
+		)
+	}
+
+	// key files which the node must read, mode 600 owned by another user makes them unreadable
+	for _, keyFile := range []struct {
+		kind string
+		path string
+	}{
+		{kind: "priv_validator_key.json", path: path.Join(home, "config", "priv_validator_key.json")},
+		{kind: "node_key.json", path: path.Join(home, "config", "node_key.json")},
+		{kind: "priv_validator_state.json", path: path.Join(home, "data", "priv_validator_state.json")},
+	} {
+		perm, exists, _, err := utils.FileInfo(keyFile.path)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check %s at %s: %v\n", keyFile.kind, keyFile.path, err)
+			return
+		}
+		if !exists {
+			continue
+		}
+		uid, _, err := utils.FileOwner(keyFile.path)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check ownership of %s: %v\n", keyFile.path, err)
+			return
+		}
+		evaluatedRule(fileTarget(keyFile.kind, keyFile.path, "owner"))
+		if uid == user.Uid {
+			continue
+		}
+		filePerm := types.FilePermFrom(perm)
+		if !filePerm.Group.Read && !filePerm.Other.Read {
+			fatalRecord(
+				fileTarget(keyFile.kind, keyFile.path, "owner"),
+				fmt.Sprintf("%s is owned by %s with mode %#o, the service User %s cannot read it", keyFile.kind, accounts.UserName(uid), perm, user.Name),
+				chownSuggest(keyFile.path, false),
+			)
+		}
+	}
+
+	for _, tree := range []struct {
+		kind string
+		name string
+	}{
+		{kind: "config-dir", name: "config"},
+		{kind: "data-dir", name: "data"},
+		{kind: "keyring-file", name: "keyring-file"},
+		{kind: "keyring-test", name: "keyring-test"},
+	} {
+		treePath := path.Join(home, tree.name)
+		_, exists, _, err := utils.FileInfo(treePath)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check %s at %s: %v\n", tree.name, treePath, err)
+			return
+		}
+		if !exists {
+			continue
+		}
+		// walk the real directory, chown -R does not follow symlink
+		if resolved, err := filepath.EvalSymlinks(treePath); err == nil {
+			treePath = resolved
+		}
+
+		evaluatedRule(fileTarget(tree.kind, treePath, "owner"))
+		mismatch, err := utils.FindOwnershipMismatch(treePath, user.Uid, user.Gid)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check ownership of %s: %v\n", treePath, err)
+			return
+		}
+
+		if len(mismatch.WrongOwner) > 0 {
+			fatalRecord(
+				fileTarget(tree.kind, treePath, "owner"),
+				fmt.Sprintf(
+					"%d file(s) under %s are not owned by the service User %s, e.g. %s",
+					len(mismatch.WrongOwner), treePath, user.Name, ownershipExamples(mismatch.WrongOwner, func(filePath string) string {
+						uid, _, _ := utils.FileOwner(filePath)
+						return accounts.UserName(uid)
+					}),
+				),
+				chownSuggest(treePath, true),
+			)
+		} else if len(mismatch.WrongGroup) > 0 {
+			warnRecord(
+				fileTarget(tree.kind, treePath, "owner"),
+				fmt.Sprintf(
+					"%d file(s) under %s are not in the primary group %s of the service User %s, e.g. %s",
+					len(mismatch.WrongGroup), treePath, groupName, user.Name, ownershipExamples(mismatch.WrongGroup, func(filePath string) string {
+						_, gid, _ := utils.FileOwner(filePath)
+						return accounts.GroupName(gid)
+					}),
+				),
+				chownSuggest(treePath, true),
+			)
+		}
+	}
+}
+
+func ownershipExamples(filePaths []string, owner func(filePath string) string) string {
+	var examples []string
+	for i, filePath := range filePaths {
+		if i >= ownershipExamplesLimit {
+			examples = append(examples, "...")
+			break
+		}
+		examples = append(examples, fmt.Sprintf("%s (%s)", filePath, owner(filePath)))
+	}
+	return strings.Join(examples, ", ")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// sensitiveHomeEntries are keys & signing state, relative to the home, must not be symlinked into places accessible by others.
+var sensitiveHomeEntries = map[string]string{
+	"config/priv_validator_key.json": "priv_validator_key.json",
+	"config/node_key.json":           "node_key.json",
+	"data/priv_validator_state.json": "priv_validator_state.json",
+	"keyring-file":                   "keyring-file",
+	"keyring-test":                   "keyring-test",
+}
+
+// checkHomeSymlinks inspects symlinks within the home, like data symlinked to another disk.
+// Permission of the targets are checked by other checks since they follow symlinks,
+// this checks the parent directories along the resolved path, which can be used to swap the target.
+func checkHomeSymlinks(home string) {
+	entries := []string{".", "config", "data", "keyring-file", "keyring-test", "wasm"}
+	for _, dir := range []string{"config", "data"} {
+		dirEntries, err := os.ReadDir(path.Join(home, dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			exitWithErrorMsgf("ERR: failed to read %s directory: %v\n", dir, err)
+			return
+		}
+		for _, dirEntry := range dirEntries {
+			entries = append(entries, path.Join(dir, dirEntry.Name()))
+		}
+	}
+
+	for _, entry := range entries {
+		entryPath := path.Join(home, entry)
+		isSymlink, target, err := utils.LinkInfo(entryPath)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check symlink %s: %v\n", entryPath, err)
+			return
+		}
+		if !isSymlink {
+			continue
+		}
+
+		kind, isSensitive := sensitiveHomeEntries[entry]
+		if !isSensitive {
+			kind = "symlink"
+		}
+		evaluatedRule(fileTarget(kind, entryPath, "symlink-broken"))
+
+		if target == "" {
+			linkTo, _ := os.Readlink(entryPath)
+			fatalRecord(
+				fileTarget(kind, entryPath, "symlink-broken"),
+				fmt.Sprintf("%s is a broken symlink to %s", entryPath, linkTo),
+				"fix or remove the symlink",
+			)
+			continue
+		}
+
+		// relative when the home is relative, parents above the working directory must be checked too
+		target, err = filepath.Abs(target)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to get absolute path of symlink target %s: %v\n", target, err)
+			return
+		}
+
+		targetPerm, _, _, err := utils.FileInfo(target)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to check symlink target %s: %v\n", target, err)
+			return
+		}
+		evaluatedRule(fileTarget(kind, entryPath, "symlink-target"), fileTarget(kind, entryPath, "symlink-parent"))
+		if targetPerm&0o002 != 0 {
+			fatalRecord(
+				fileTarget(kind, entryPath, "symlink-target"),
+				fmt.Sprintf("%s is symlinked to %s which is writable by others (%s)", entryPath, target, targetPerm.String()),
+				"chmod o-w "+target,
+			)
+		}
+
+		for _, parent := range utils.ParentDirs(target) {
+			fi, err := os.Stat(parent)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to check directory %s: %v\n", parent, err)
+				return
+			}
+			if fi.Mode().Perm()&0o002 != 0 && fi.Mode()&os.ModeSticky == 0 {
+				fatalRecord(
+					fileTarget(kind, entryPath, "symlink-parent"),
+					fmt.Sprintf("%s is symlinked to %s, parent directory %s is writable by others, anyone can replace the target", entryPath, target, parent),
+					"chmod o-w "+parent,
+				)
+			}
+		}
+
+		if isSensitive {
+			targetDir := filepath.Dir(target)
+			perm, _, _, err := utils.FileInfo(targetDir)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to check directory %s: %v\n", targetDir, err)
+				return
+			}
+			evaluatedRule(fileTarget(kind, entryPath, "symlink-target-dir"))
+			if perm&0o007 != 0 {
+				fatalRecord(
+					fileTarget(kind, entryPath, "symlink-target-dir"),
+					fmt.Sprintf("%s is symlinked into %s which is accessible by others (%s)", kind, targetDir, perm.String()),
+					fmt.Sprintf("chmod o-rwx %s, or move the target into a private directory", targetDir),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckHomeSymlinks_RelativeHome(t *testing.T) {
+	resetCheckRecords(t)
+
+	// root is writable by others without sticky bit, above the working directory
+	root := t.TempDir()
+	if err := os.Chmod(root, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	workDir := filepath.Join(root, "work")
+	for _, dir := range []string{
+		filepath.Join(workDir, "home"),
+		filepath.Join(workDir, "disk", "data"),
+	} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "disk", "data"), filepath.Join(workDir, "home", "data")); err != nil {
+		t.Fatal(err)
+	}
+
+	originalWorkDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(originalWorkDir)
+	})
+
+	checkHomeSymlinks("home")
+
+	var found bool
+	for _, record := range checkRecords {
+		if strings.HasSuffix(record.target.rule, "/symlink-parent") && strings.Contains(record.message, "parent directory "+root+" ") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("want symlink-parent record of %s, got %+v", root, checkRecords)
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/sergeymakinen/go-systemdconf/v2"
+	"github.com/sergeymakinen/go-systemdconf/v2/unit"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hostRequirement is the hardware requirement of a node type.
+type hostRequirement struct {
+	minimumCores    int
+	recommendCores  int
+	minimumMemory   uint64
+	recommendMemory uint64
+}
+
+var hostRequirements = map[types.NodeType]hostRequirement{
+	types.ValidatorNode: {minimumCores: 4, recommendCores: 8, minimumMemory: 16 * gib, recommendMemory: 32 * gib},
+	types.RpcNode:       {minimumCores: 8, recommendCores: 16, minimumMemory: 32 * gib, recommendMemory: 64 * gib},
+	types.SnapshotNode:  {minimumCores: 4, recommendCores: 8, minimumMemory: 16 * gib, recommendMemory: 32 * gib},
+	types.ArchivalNode:  {minimumCores: 8, recommendCores: 16, minimumMemory: 32 * gib, recommendMemory: 64 * gib},
+}
+
+// minimumNoFile is the open files limit that node needs, each peer, RPC connection and database file holds a descriptor.
+const minimumNoFile = 65535
+
+// networkFsTypes are filesystems where database latency and locking are not reliable.
+var networkFsTypes = map[string]bool{
+	"nfs":        true,
+	"nfs4":       true,
+	"cifs":       true,
+	"smb3":       true,
+	"smbfs":      true,
+	"fuse.sshfs": true,
+	"glusterfs":  true,
+	"ceph":       true,
+	"9p":         true,
+}
+
+// checkHost checks the machine that the node is running on, against the requirement of the node type.
+// Machine information is read from procfs mounted at procRoot.
+func checkHost(home string, nodeType types.NodeType, procRoot string, serviceFilePath string) {
+	hostTarget := func(rule string) recordTarget {
+		return ruleTarget("host", rule)
+	}
+
+	requirement := hostRequirements[nodeType]
+
+	cores, err := utils.CountCpuCores(procRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to count CPU cores: %v\n", err)
+		return
+	}
+	evaluatedRule(hostTarget("cpu-cores"))
+	if cores < requirement.minimumCores {
+		fatalRecord(
+			hostTarget("cpu-cores"),
+			fmt.Sprintf("only %d CPU cores, %s node requires at least %d", cores, nodeType, requirement.minimumCores),
+			fmt.Sprintf("upgrade to at least %d cores", requirement.recommendCores),
+		)
+	} else if cores < requirement.recommendCores {
+		warnRecord(
+			hostTarget("cpu-cores"),
+			fmt.Sprintf("%d CPU cores, recommended for %s node is %d", cores, nodeType, requirement.recommendCores),
+			fmt.Sprintf("upgrade to %d cores", requirement.recommendCores),
+		)
+	}
+
+	memInfo, err := utils.ReadMemInfo(procRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read memory info: %v\n", err)
+		return
+	}
+	evaluatedRule(hostTarget("memory"))
+	// kernel reserves some memory, total is always a bit less than the installed amount
+	const reservedMemoryTolerance = 5 * gib / 10
+	if memInfo.MemTotal+reservedMemoryTolerance < requirement.minimumMemory {
+		fatalRecord(
+			hostTarget("memory"),
+			fmt.Sprintf("only %s RAM, %s node requires at least %s", formatBytes(int64(memInfo.MemTotal)), nodeType, formatBytes(int64(requirement.minimumMemory))),
+			fmt.Sprintf("upgrade to at least %s RAM", formatBytes(int64(requirement.recommendMemory))),
+		)
+	} else if memInfo.MemTotal+reservedMemoryTolerance < requirement.recommendMemory {
+		warnRecord(
+			hostTarget("memory"),
+			fmt.Sprintf("%s RAM, recommended for %s node is %s", formatBytes(int64(memInfo.MemTotal)), nodeType, formatBytes(int64(requirement.recommendMemory))),
+			fmt.Sprintf("upgrade to %s RAM", formatBytes(int64(requirement.recommendMemory))),
+		)
+	}
+
+	swapEnabled, err := utils.IsSwapEnabled(procRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check swap status: %v\n", err)
+		return
+	}
+	if nodeType == types.ValidatorNode {
+		evaluatedRule(hostTarget("swap"))
+	}
+	if swapEnabled && nodeType == types.ValidatorNode {
+		warnRecord(
+			hostTarget("swap"),
+			"swap is enabled, validator swapping out memory will miss blocks",
+			"sudo swapoff -a, then remove swap entries from /etc/fstab",
+		)
+	}
+
+	fileMax, err := utils.ReadFileMax(procRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read system-wide open files limit: %v\n", err)
+		return
+	}
+	evaluatedRule(hostTarget("file-max"))
+	if fileMax < minimumNoFile {
+		fatalRecord(
+			hostTarget("file-max"),
+			fmt.Sprintf("system-wide open files limit fs.file-max is %d, lower than %d", fileMax, minimumNoFile),
+			fmt.Sprintf("add fs.file-max=%d to /etc/sysctl.conf, then sudo sysctl -p", 2*minimumNoFile),
+		)
+	}
+
+	if serviceFilePath != "" {
+		checkHostServiceNoFile(serviceFilePath)
+	}
+
+	checkHostMount(home, nodeType, procRoot)
+}
+
+// checkHostServiceNoFile checks LimitNOFILE of the service, default limit of systemd is too low for node.
+func checkHostServiceNoFile(serviceFilePath string) {
+	bz, err := os.ReadFile(serviceFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read service file: %v\n", err)
+		return
+	}
+
+	var sf unit.ServiceFile
+	err = systemdconf.Unmarshal(bz, &sf)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to unmarshal service file: %v\n", err)
+		return
+	}
+
+	target := settingTarget("service", serviceFilePath, "Service.LimitNOFILE")
+	evaluatedRule(target)
+	limitNoFile := strings.TrimSpace(sf.Service.LimitNOFILE.String())
+	if limitNoFile == "" {
+		fatalRecord(target, "service file is missing LimitNOFILE in [Service] section", fmt.Sprintf("add LimitNOFILE=%d to [Service] section", minimumNoFile))
+		return
+	}
+	if strings.EqualFold(limitNoFile, "infinity") {
+		return
+	}
+
+	// soft:hard
+	soft, _, _ := strings.Cut(limitNoFile, ":")
+	limit, err := strconv.ParseUint(soft, 10, 64)
+	if err != nil {
+		fatalRecord(target, fmt.Sprintf("invalid LimitNOFILE %q in [Service] section", limitNoFile), fmt.Sprintf("change LimitNOFILE=%d", minimumNoFile))
+		return
+	}
+	if limit < minimumNoFile {
+		fatalRecord(target, fmt.Sprintf("LimitNOFILE in [Service] section is %d, lower than %d", limit, minimumNoFile), fmt.Sprintf("change LimitNOFILE=%d", minimumNoFile))
+	}
+}
+
+// checkHostMount checks the filesystem which the home is mounted on.
+func checkHostMount(home string, nodeType types.NodeType, procRoot string) {
+	absHome, err := filepath.Abs(home)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to get absolute path of home %s: %v\n", home, err)
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(absHome); err == nil {
+		absHome = resolved
+	}
+
+	mounts, err := utils.ReadMounts(procRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read mounts: %v\n", err)
+		return
+	}
+	mount := utils.FindMount(mounts, absHome)
+	if mount == nil {
+		warnRecord(ruleTarget("host", "mount"), fmt.Sprintf("could not find the filesystem that home %s is mounted on", absHome), "")
+		return
+	}
+
+	mountTarget := fileTarget("host", absHome, "mount")
+	evaluatedRule(mountTarget)
+	if mount.FsType == "tmpfs" || mount.FsType == "ramfs" {
+		fatalRecord(
+			mountTarget,
+			fmt.Sprintf("home is on %s mounted at %s, data will be lost on reboot", mount.FsType, mount.MountPoint),
+			"move home to a persistent disk",
+		)
+	}
+	if mount.IsReadOnly() {
+		fatalRecord(
+			mountTarget,
+			fmt.Sprintf("home is on read-only filesystem mounted at %s", mount.MountPoint),
+			"remount as read-write or move home to a writable disk",
+		)
+	}
+	if networkFsTypes[mount.FsType] {
+		if nodeType == types.ValidatorNode {
+			fatalRecord(
+				mountTarget,
+				fmt.Sprintf("home is on network filesystem %s mounted at %s, validator must use local disk", mount.FsType, mount.MountPoint),
+				"move home to a local NVMe disk",
+			)
+		} else {
+			warnRecord(
+				mountTarget,
+				fmt.Sprintf("home is on network filesystem %s mounted at %s, database will be slow", mount.FsType, mount.MountPoint),
+				"move home to a local NVMe disk",
+			)
+		}
+	}
+}
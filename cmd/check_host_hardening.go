@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"path/filepath"
+	"strings"
+)
+
+const reloadSshdSuggest = "then validate by sudo sshd -t and sudo systemctl reload ssh, keep current session open until logged in successfully with a new session"
+
+// checkHostHardening audits the effective global settings of sshd, including files included from sshd_config.d.
+func checkHostHardening(nodeType types.NodeType, etcRoot string) {
+	hardeningRecord := warnRecord
+	if nodeType == types.ValidatorNode {
+		hardeningRecord = fatalRecord
+	}
+
+	sshdConfigFilePath := filepath.Join(etcRoot, "ssh", "sshd_config")
+	evaluatedRule(fileTarget("sshd", sshdConfigFilePath, "config"))
+	_, exists, _, err := utils.FileInfo(sshdConfigFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check sshd_config at %s: %v\n", sshdConfigFilePath, err)
+		return
+	}
+	if !exists {
+		warnRecord(fileTarget("sshd", sshdConfigFilePath, "config"), "sshd_config does not exist, unable to audit SSH settings", "")
+		return
+	}
+
+	config, err := utils.ReadSshdConfig(sshdConfigFilePath, etcRoot)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read sshd_config: %v\n", err)
+		return
+	}
+
+	// suggestSetting suggests the exact edit, sshd uses the first obtained value so the edit must be at the effective line
+	suggestSetting := func(setting utils.SshdConfigSetting, found bool, keyword, value string) string {
+		if found {
+			return fmt.Sprintf("change to \"%s %s\" at %s:%d, %s", keyword, value, setting.File, setting.Line, reloadSshdSuggest)
+		}
+		return fmt.Sprintf("add \"%s %s\" at the top of %s, %s", keyword, value, sshdConfigFilePath, reloadSshdSuggest)
+	}
+	sshdTarget := func(setting utils.SshdConfigSetting, found bool, keyword string) recordTarget {
+		if found {
+			return lineTarget("sshd", setting.File, keyword, setting.Line)
+		}
+		return fileTarget("sshd", sshdConfigFilePath, keyword)
+	}
+
+	evaluatedRule(
+		fileTarget("sshd", sshdConfigFilePath, "PermitRootLogin"),
+		fileTarget("sshd", sshdConfigFilePath, "PasswordAuthentication"),
+		fileTarget("sshd", sshdConfigFilePath, "AllowUsers"),
+	)
+	if setting, found := config.Get("PermitRootLogin"); found && strings.EqualFold(setting.Value, "yes") {
+		hardeningRecord(
+			sshdTarget(setting, found, "PermitRootLogin"),
+			"SSH allows root login",
+			suggestSetting(setting, found, "PermitRootLogin", "no"),
+		)
+	}
+
+	// PasswordAuthentication defaults to yes
+	if setting, found := config.Get("PasswordAuthentication"); !found || strings.EqualFold(setting.Value, "yes") {
+		message := "SSH allows password authentication"
+		if !found {
+			message += ", PasswordAuthentication is not set and defaults to yes"
+		}
+		hardeningRecord(
+			sshdTarget(setting, found, "PasswordAuthentication"),
+			message,
+			"ensure SSH key login works, "+suggestSetting(setting, found, "PasswordAuthentication", "no"),
+		)
+	}
+
+	_, foundAllowUsers := config.Get("AllowUsers")
+	_, foundAllowGroups := config.Get("AllowGroups")
+	if !foundAllowUsers && !foundAllowGroups {
+		warnRecord(
+			fileTarget("sshd", sshdConfigFilePath, "AllowUsers"),
+			"SSH does not restrict which users can log in, AllowUsers is missing",
+			"do not allow the user running the node, "+suggestSetting(utils.SshdConfigSetting{}, false, "AllowUsers", "<admin-user>"),
+		)
+	}
+
+	if nodeType == types.ValidatorNode {
+		evaluatedRule(fileTarget("sshd", sshdConfigFilePath, "Port"))
+		ports := config.GetAll("Port")
+		var port22 *utils.SshdConfigSetting
+		for i, port := range ports {
+			if port.Value == "22" {
+				port22 = &ports[i]
+				break
+			}
+		}
+		if len(ports) == 0 || port22 != nil {
+			var setting utils.SshdConfigSetting
+			if port22 != nil {
+				setting = *port22
+			}
+			warnRecord(
+				sshdTarget(setting, port22 != nil, "Port"),
+				"SSH is listening on the default port 22 on validator node",
+				"allow the new port on firewall first, "+suggestSetting(setting, port22 != nil, "Port", "<custom port>"),
+			)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/bcdevtools/node-setup-check/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCheckHost_ProcFixtures(t *testing.T) {
+	// rule => fatal or warn
+	const fatal, warn = "fatal", "warn"
+	tests := []struct {
+		procRoot string
+		nodeType types.NodeType
+		want     map[string]string
+	}{
+		// 4 cores, 15.6 GiB RAM, swap on
+		{procRoot: "small", nodeType: types.ValidatorNode, want: map[string]string{"host/cpu-cores": warn, "host/memory": warn, "host/swap": warn}},
+		{procRoot: "small", nodeType: types.RpcNode, want: map[string]string{"host/cpu-cores": fatal, "host/memory": fatal}},
+		{procRoot: "small", nodeType: types.SnapshotNode, want: map[string]string{"host/cpu-cores": warn, "host/memory": warn}},
+		{procRoot: "small", nodeType: types.ArchivalNode, want: map[string]string{"host/cpu-cores": fatal, "host/memory": fatal}},
+		// 16 cores, 63.7 GiB RAM, no swap
+		{procRoot: "recommended", nodeType: types.ValidatorNode, want: map[string]string{}},
+		{procRoot: "recommended", nodeType: types.RpcNode, want: map[string]string{}},
+		{procRoot: "recommended", nodeType: types.ArchivalNode, want: map[string]string{}},
+		// 2 cores, 7.7 GiB RAM, fs.file-max 8192, root on NFS
+		{procRoot: "undersized", nodeType: types.ValidatorNode, want: map[string]string{"host/cpu-cores": fatal, "host/memory": fatal, "host/file-max": fatal, "host/mount": fatal}},
+		{procRoot: "undersized", nodeType: types.RpcNode, want: map[string]string{"host/cpu-cores": fatal, "host/memory": fatal, "host/file-max": fatal, "host/mount": warn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.procRoot+"_"+tt.nodeType.String(), func(t *testing.T) {
+			resetCheckRecords(t)
+
+			checkHost(t.TempDir(), tt.nodeType, filepath.Join("testdata", "proc", tt.procRoot), "")
+
+			got := make(map[string]string)
+			for _, record := range checkRecords {
+				severity := warn
+				if record.fatal {
+					severity = fatal
+				}
+				got[record.target.rule] = severity
+			}
+			if describeRecords(got) != describeRecords(tt.want) {
+				t.Fatalf("want records %s, got %s", describeRecords(tt.want), describeRecords(got))
+			}
+		})
+	}
+}
+
+func describeRecords(records map[string]string) string {
+	var parts []string
+	for rule, severity := range records {
+		parts = append(parts, rule+"="+severity)
+	}
+	sort.Strings(parts)
+	return "[" + strings.Join(parts, ", ") + "]"
+}
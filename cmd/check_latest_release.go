@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/constants"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pkg/errors"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const defaultReleaseEndpoint = "https://api.github.com/repos/bcdevtools/node-setup-check/releases/latest"
+
+type latestReleaseCheckOptions struct {
+	offline   bool // for air-gapped machines, nothing is requested
+	endpoint  string
+	timeout   time.Duration
+	cacheFile string // empty means no cache
+	cacheTtl  time.Duration
+}
+
+type latestReleaseCheckResult struct {
+	tagName   string
+	fromCache bool
+	err       error
+}
+
+type latestReleaseCache struct {
+	Endpoint  string    `json:"endpoint"`
+	TagName   string    `json:"tag_name"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// defaultLatestReleaseCacheFile returns the path of the file used to cache the latest release,
+// or empty if the user cache directory is not available.
+func defaultLatestReleaseCacheFile() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil || cacheDir == "" {
+		return ""
+	}
+	return path.Join(cacheDir, constants.BINARY_NAME, "latest_release.json")
+}
+
+// startLatestReleaseCheck fetches the latest release in background, from cache if still fresh.
+// The result is delivered exactly once to the returned channel, nil is returned in offline mode.
+func startLatestReleaseCheck(opts latestReleaseCheckOptions) <-chan latestReleaseCheckResult {
+	if opts.offline {
+		return nil
+	}
+
+	resultChan := make(chan latestReleaseCheckResult, 1)
+
+	go func() {
+		if tagName, found := readLatestReleaseCache(opts); found {
+			resultChan <- latestReleaseCheckResult{tagName: tagName, fromCache: true}
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+		defer cancel()
+
+		tagName, err := fetchLatestRelease(ctx, http.DefaultClient, opts.endpoint)
+		if err == nil {
+			writeLatestReleaseCache(opts, tagName)
+		}
+
+		resultChan <- latestReleaseCheckResult{tagName: tagName, err: err}
+	}()
+
+	return resultChan
+}
+
+// awaitLatestReleaseCheck waits for the result of the latest release check until the deadline,
+// then put a warning record if the current version is outdated.
+func awaitLatestReleaseCheck(resultChan <-chan latestReleaseCheckResult, deadline time.Duration) {
+	var result latestReleaseCheckResult
+	select {
+	case result = <-resultChan:
+	case <-time.After(deadline):
+		printfStdErr("WARN: timed out checking latest release after %s, use --%s to skip\n", deadline, flagOffline)
+		return
+	}
+
+	if result.err != nil {
+		printfStdErr("WARN: failed to check latest release: %v, use --%s to skip\n", result.err, flagOffline)
+		return
+	}
+
+	evaluatedRule(ruleTarget("nodesc", "latest-release"))
+	if isOutdatedVersion(constants.VERSION, result.tagName) {
+		tagName := "v" + strings.TrimPrefix(result.tagName, "v")
+		warnRecord(
+			ruleTarget("nodesc", "latest-release"),
+			fmt.Sprintf("latest release is %s, must use latest version to prevent bugs and new logics", tagName),
+			fmt.Sprintf("cd ~ && go install github.com/bcdevtools/node-setup-check/cmd/nodesc@%s", tagName),
+		)
+	}
+}
+
+// isOutdatedVersion returns true if the latest version has higher precedence than the current version.
+// Current version which is not a semantic version, like a build from a branch, is considered as a dev build
+// and never be reported as outdated.
+func isOutdatedVersion(currentVersion, latestVersion string) bool {
+	current, ok := utils.ParseSemver(currentVersion)
+	if !ok {
+		return false
+	}
+
+	latest, ok := utils.ParseSemver(latestVersion)
+	if !ok {
+		return false
+	}
+
+	return current.Compare(latest) < 0
+}
+
+func fetchLatestRelease(ctx context.Context, client *http.Client, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to request latest release")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode latest release")
+	}
+
+	if release.TagName == "" {
+		return "", fmt.Errorf("missing tag_name in latest release")
+	}
+
+	return release.TagName, nil
+}
+
+func readLatestReleaseCache(opts latestReleaseCheckOptions) (tagName string, found bool) {
+	if opts.cacheFile == "" || opts.cacheTtl <= 0 {
+		return
+	}
+
+	bz, err := os.ReadFile(opts.cacheFile)
+	if err != nil {
+		return
+	}
+
+	var cache latestReleaseCache
+	if err := json.Unmarshal(bz, &cache); err != nil {
+		return
+	}
+
+	if cache.Endpoint != opts.endpoint || cache.TagName == "" {
+		return
+	}
+
+	if time.Since(cache.CheckedAt) > opts.cacheTtl {
+		return
+	}
+
+	return cache.TagName, true
+}
+
+func writeLatestReleaseCache(opts latestReleaseCheckOptions, tagName string) {
+	if opts.cacheFile == "" || opts.cacheTtl <= 0 {
+		return
+	}
+
+	bz, err := json.Marshal(latestReleaseCache{
+		Endpoint:  opts.endpoint,
+		TagName:   tagName,
+		CheckedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return
+	}
+
+	// caching is best-effort, failure should not interrupt the check
+	if err := os.MkdirAll(path.Dir(opts.cacheFile), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(opts.cacheFile, bz, 0o600)
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/bcdevtools/node-setup-check/constants"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newReleaseStub serves the latest release like GitHub API and counts the requests.
+func newReleaseStub(t *testing.T, tagName string, delay <-chan struct{}) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if delay != nil {
+			<-delay
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"tag_name": tagName})
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func resetCheckRecords(t *testing.T) {
+	checkRecords = nil
+	evaluatedRules = nil
+	t.Cleanup(func() {
+		checkRecords = nil
+		evaluatedRules = nil
+	})
+}
+
+func setVersion(t *testing.T, version string) {
+	original := constants.VERSION
+	constants.VERSION = version
+	t.Cleanup(func() {
+		constants.VERSION = original
+	})
+}
+
+func TestLatestReleaseCheck_Cache(t *testing.T) {
+	server, hits := newReleaseStub(t, "v1.3.0", nil)
+	opts := latestReleaseCheckOptions{
+		endpoint:  server.URL,
+		timeout:   time.Second,
+		cacheFile: filepath.Join(t.TempDir(), "latest_release.json"),
+		cacheTtl:  time.Hour,
+	}
+
+	result := <-startLatestReleaseCheck(opts)
+	if result.err != nil || result.tagName != "v1.3.0" || result.fromCache {
+		t.Fatalf("first check should fetch, got %+v", result)
+	}
+
+	result = <-startLatestReleaseCheck(opts)
+	if result.err != nil || result.tagName != "v1.3.0" || !result.fromCache {
+		t.Fatalf("second check should be served from cache, got %+v", result)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 request, got %d", got)
+	}
+
+	// expired cache
+	bz, _ := json.Marshal(latestReleaseCache{Endpoint: server.URL, TagName: "v1.2.0", CheckedAt: time.Now().Add(-2 * time.Hour)})
+	if err := os.WriteFile(opts.cacheFile, bz, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result = <-startLatestReleaseCheck(opts)
+	if result.fromCache || result.tagName != "v1.3.0" {
+		t.Fatalf("expired cache should be refreshed, got %+v", result)
+	}
+
+	// cache of another endpoint
+	bz, _ = json.Marshal(latestReleaseCache{Endpoint: "http://other.invalid", TagName: "v1.2.0", CheckedAt: time.Now()})
+	if err := os.WriteFile(opts.cacheFile, bz, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result = <-startLatestReleaseCheck(opts)
+	if result.fromCache || result.tagName != "v1.3.0" {
+		t.Fatalf("cache of another endpoint should not be used, got %+v", result)
+	}
+
+	// cache disabled
+	opts.cacheTtl = 0
+	result = <-startLatestReleaseCheck(opts)
+	if result.fromCache {
+		t.Fatalf("cache should not be used when ttl is 0, got %+v", result)
+	}
+	if got := hits.Load(); got != 4 {
+		t.Fatalf("expected 4 requests, got %d", got)
+	}
+}
+
+func TestLatestReleaseCheck_Offline(t *testing.T) {
+	server, hits := newReleaseStub(t, "v1.3.0", nil)
+
+	resultChan := startLatestReleaseCheck(latestReleaseCheckOptions{
+		offline:  true,
+		endpoint: server.URL,
+		timeout:  time.Second,
+	})
+	if resultChan != nil {
+		t.Fatal("offline mode should not start the check")
+	}
+	if got := hits.Load(); got != 0 {
+		t.Fatalf("offline mode should not request, got %d requests", got)
+	}
+}
+
+func TestAwaitLatestReleaseCheck_Deadline(t *testing.T) {
+	resetCheckRecords(t)
+	setVersion(t, "1.0.0")
+
+	unblock := make(chan struct{})
+	server, _ := newReleaseStub(t, "v9.0.0", unblock)
+	t.Cleanup(func() {
+		close(unblock) // before server.Close, which waits for the blocked handler
+	})
+
+	resultChan := startLatestReleaseCheck(latestReleaseCheckOptions{
+		endpoint: server.URL,
+		timeout:  10 * time.Second,
+	})
+
+	startTime := time.Now()
+	awaitLatestReleaseCheck(resultChan, 50*time.Millisecond)
+	if elapsed := time.Since(startTime); elapsed > time.Second {
+		t.Fatalf("should give up at the deadline, waited %s", elapsed)
+	}
+	if len(checkRecords) != 0 {
+		t.Fatalf("timeout should not produce records, got %d", len(checkRecords))
+	}
+}
+
+func TestAwaitLatestReleaseCheck_Outdated(t *testing.T) {
+	tests := []struct {
+		name           string
+		currentVersion string
+		latestTag      string
+		wantRecord     bool
+	}{
+		{name: "outdated", currentVersion: "1.2.1", latestTag: "v1.3.0", wantRecord: true},
+		{name: "up to date", currentVersion: "1.3.0", latestTag: "v1.3.0", wantRecord: false},
+		{name: "newer than latest", currentVersion: "1.4.0-rc.1", latestTag: "v1.3.0", wantRecord: false},
+		{name: "git describe build after the tag", currentVersion: "1.3.0-3-gabcdef0", latestTag: "v1.3.0", wantRecord: false},
+		{name: "dev build", currentVersion: "main", latestTag: "v1.3.0", wantRecord: false},
+		{name: "latest is not semver", currentVersion: "1.2.1", latestTag: "nightly", wantRecord: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCheckRecords(t)
+			setVersion(t, tt.currentVersion)
+			server, _ := newReleaseStub(t, tt.latestTag, nil)
+
+			awaitLatestReleaseCheck(startLatestReleaseCheck(latestReleaseCheckOptions{
+				endpoint: server.URL,
+				timeout:  time.Second,
+			}), time.Second)
+
+			if gotRecord := len(checkRecords) == 1; gotRecord != tt.wantRecord {
+				t.Fatalf("want record %t, got %d records", tt.wantRecord, len(checkRecords))
+			}
+			if tt.wantRecord && checkRecords[0].target.rule != "nodesc/latest-release" {
+				t.Fatalf("unexpected rule %s", checkRecords[0].target.rule)
+			}
+		})
+	}
+}
+
+func TestFetchLatestRelease_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	result := <-startLatestReleaseCheck(latestReleaseCheckOptions{
+		endpoint: server.URL,
+		timeout:  time.Second,
+	})
+	if result.err == nil {
+		t.Fatal("expected error on non-200 status")
+	}
+}
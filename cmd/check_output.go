@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/constants"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	outputText  = "text"
+	outputSarif = "sarif"
+	outputJunit = "junit"
+)
+
+var allOutputFormats = []string{outputText, outputSarif, outputJunit}
+
+func isValidOutputFormat(format string) bool {
+	for _, f := range allOutputFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// locateCheckRecordLines resolves line number of the offending key of each record, 0 if not found.
+func locateCheckRecordLines(records []checkRecord) []int {
+	contentCache := make(map[string][]byte)
+	lines := make([]int, len(records))
+	for i, record := range records {
+		if record.target.line > 0 {
+			lines[i] = record.target.line
+			continue
+		}
+		if record.target.file == "" || record.target.key == "" {
+			continue
+		}
+
+		content, found := contentCache[record.target.file]
+		if !found {
+			content, _ = os.ReadFile(record.target.file) // best-effort
+			contentCache[record.target.file] = content
+		}
+
+		lines[i] = utils.FindKeyLine(content, record.target.key)
+	}
+	return lines
+}
+
+func checkRecordText(record checkRecord) string {
+	if record.suggest == "" {
+		return record.message
+	}
+	return fmt.Sprintf("%s\nSuggest: %s", record.message, record.suggest)
+}
+
+// fileUri converts file path into URI used in reports, relative paths are kept relative
+// so CI can map them to files in the repository.
+func fileUri(file string) string {
+	if filepath.IsAbs(file) {
+		return "file://" + filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func writeSarifReport(w io.Writer, records []checkRecord) error {
+	lines := locateCheckRecordLines(records)
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           constants.BINARY_NAME,
+				Version:        constants.VERSION,
+				InformationUri: "https://github.com/bcdevtools/node-setup-check",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for i, record := range records {
+		idx, found := ruleIndex[record.target.rule]
+		if !found {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[record.target.rule] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				Id:               record.target.rule,
+				ShortDescription: sarifMessage{Text: sarifRuleDescription(record.target.rule)},
+			})
+		}
+
+		result := sarifResult{
+			RuleId:    record.target.rule,
+			RuleIndex: idx,
+			Level:     "warning",
+			Message:   sarifMessage{Text: checkRecordText(record)},
+		}
+		if record.fatal {
+			result.Level = "error"
+		}
+		if record.target.file != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{Uri: fileUri(record.target.file)},
+				},
+			}
+			if lines[i] > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: lines[i]}
+			}
+			result.Locations = append(result.Locations, location)
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleDescription is the same for every run, code scanning groups results by rule,
+// values of the finding are in the message of the result.
+func sarifRuleDescription(rule string) string {
+	kind, name, found := strings.Cut(rule, "/")
+	if !found {
+		return rule
+	}
+	return fmt.Sprintf("Check %s of %s", name, kind)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string         `xml:"classname,attr"`
+	Name      string         `xml:"name,attr"`
+	File      string         `xml:"file,attr,omitempty"`
+	Line      int            `xml:"line,attr,omitempty"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJunitReport writes check records as JUnit XML, each evaluated rule is a test case,
+// records of the same rule are failures of that test case, rules without record are passing test cases.
+func writeJunitReport(w io.Writer, suiteName string, rules []string, records []checkRecord) error {
+	lines := locateCheckRecordLines(records)
+
+	suite := junitTestSuite{
+		Name:      suiteName,
+		TestCases: []junitTestCase{},
+	}
+
+	testCaseIndex := make(map[string]int)
+	addTestCase := func(rule string) int {
+		if idx, found := testCaseIndex[rule]; found {
+			return idx
+		}
+		className := rule
+		if sep := strings.Index(className, "/"); sep > 0 {
+			className = className[:sep]
+		}
+		testCaseIndex[rule] = len(suite.TestCases)
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: className,
+			Name:      rule,
+		})
+		return len(suite.TestCases) - 1
+	}
+	for _, rule := range rules {
+		addTestCase(rule)
+	}
+
+	for i, record := range records {
+		idx := addTestCase(record.target.rule)
+		testCase := &suite.TestCases[idx]
+		if len(testCase.Failures) == 0 {
+			testCase.File = record.target.file
+			testCase.Line = lines[i]
+			suite.Failures++
+		}
+
+		failureType := "warning"
+		if record.fatal {
+			failureType = "fatal"
+		}
+
+		var text strings.Builder
+		text.WriteString(checkRecordText(record))
+		if record.target.file != "" {
+			text.WriteString("\nFile: " + record.target.file)
+			if lines[i] > 0 {
+				text.WriteString(fmt.Sprintf(":%d", lines[i]))
+			}
+		}
+
+		testCase.Failures = append(testCase.Failures, junitFailure{
+			Type:    failureType,
+			Message: record.message,
+			Text:    text.String(),
+		})
+	}
+	if len(suite.TestCases) == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: constants.BINARY_NAME,
+			Name:      constants.BINARY_NAME + "/all-checks",
+		})
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{
+		Name:     constants.BINARY_NAME,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
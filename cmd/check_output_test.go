@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJunitReport_PassingRules(t *testing.T) {
+	rules := []string{"app.toml/pruning", "app.toml/api.enable", "config.toml/moniker"}
+	records := []checkRecord{
+		{fatal: true, target: ruleTarget("app.toml", "api.enable"), message: "api is disabled"},
+		{fatal: false, target: ruleTarget("app.toml", "api.enable"), message: "another finding"},
+		{fatal: true, target: ruleTarget("nodesc", "aborted"), message: "aborted"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJunitReport(&buf, "suite", rules, records); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if report.Tests != 4 || report.Failures != 2 {
+		t.Fatalf("want 4 tests with 2 failures, got %d tests with %d failures", report.Tests, report.Failures)
+	}
+
+	failuresOf := make(map[string]int)
+	for _, testCase := range report.Suites[0].TestCases {
+		failuresOf[testCase.Name] = len(testCase.Failures)
+	}
+	for rule, want := range map[string]int{
+		"app.toml/pruning":    0,
+		"app.toml/api.enable": 2,
+		"config.toml/moniker": 0,
+		"nodesc/aborted":      1,
+	} {
+		if got, found := failuresOf[rule]; !found || got != want {
+			t.Fatalf("want test case %s with %d failures, got %d (found %t)", rule, want, got, found)
+		}
+	}
+}
+
+func TestWriteJunitReport_NoRules(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJunitReport(&buf, "suite", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Tests != 1 || report.Suites[0].TestCases[0].Name != "nodesc/all-checks" {
+		t.Fatalf("want the all-checks test case, got %+v", report.Suites[0].TestCases)
+	}
+}
+
+func TestWriteSarifReport_StableRules(t *testing.T) {
+	writeRules := func(records []checkRecord) (string, sarifRun) {
+		var buf bytes.Buffer
+		if err := writeSarifReport(&buf, records); err != nil {
+			t.Fatal(err)
+		}
+		var report sarifReport
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		rules, err := json.Marshal(report.Runs[0].Tool.Driver.Rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(rules), report.Runs[0]
+	}
+
+	rules1, run1 := writeRules([]checkRecord{
+		{fatal: true, target: ruleTarget("host", "cpu-cores"), message: "only 2 CPU cores, validator node requires at least 4"},
+		{fatal: false, target: ruleTarget("host", "memory"), message: "16 GiB RAM, recommended for validator node is 32 GiB"},
+		{fatal: false, target: ruleTarget("host", "memory"), message: "another finding"},
+	})
+	rules2, run2 := writeRules([]checkRecord{
+		{fatal: true, target: ruleTarget("host", "cpu-cores"), message: "only 1 CPU cores, validator node requires at least 4"},
+		{fatal: false, target: ruleTarget("host", "memory"), message: "8 GiB RAM, recommended for validator node is 32 GiB"},
+	})
+
+	if rules1 != rules2 {
+		t.Errorf("rules differ between runs:\n%s\n%s", rules1, rules2)
+	}
+	if got := run1.Tool.Driver.Rules[0].ShortDescription.Text; got != "Check cpu-cores of host" {
+		t.Errorf("short description = %q", got)
+	}
+	if len(run1.Results) != 3 || run1.Results[2].RuleIndex != 1 {
+		t.Fatalf("want 3 results, the last one of rule index 1, got %+v", run1.Results)
+	}
+	if !strings.Contains(run1.Results[0].Message.Text, "only 2 CPU cores") || !strings.Contains(run2.Results[0].Message.Text, "only 1 CPU cores") {
+		t.Errorf("result messages should hold the details, got %q and %q", run1.Results[0].Message.Text, run2.Results[0].Message.Text)
+	}
+}
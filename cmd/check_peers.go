@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"net"
+	"path"
+)
+
+// checkHomeConfigPeers validates each entry of seeds and persistent_peers.
+// Loopback, private and link-local addresses are only allowed when nodes are connected via private network.
+func checkHomeConfigPeers(configPath string, configToml *types.ConfigToml, privateNetwork bool) {
+	configTomlFilePath := path.Join(configPath, "config.toml")
+
+	peersOf := make(map[string][]*utils.Peer)
+	for _, key := range []string{"seeds", "persistent_peers"} {
+		list := configToml.P2P.Seeds
+		if key == "persistent_peers" {
+			list = configToml.P2P.PersistentPeers
+		}
+		target := settingTarget("config.toml", configTomlFilePath, "p2p."+key)
+		evaluatedRule(target)
+
+		entriesOfId := make(map[string]string)
+		entriesOfAddress := make(map[string]string)
+		for _, entry := range utils.SplitPeerList(list) {
+			peer, err := utils.ParsePeer(entry)
+			if err != nil {
+				warnRecord(target, fmt.Sprintf("invalid entry %q in %s: %v", entry, key, err), "correct the entry to <node ID>@<host>:<port>")
+				continue
+			}
+
+			if firstEntry, duplicated := entriesOfId[peer.Id]; duplicated {
+				warnRecord(target, fmt.Sprintf("node ID %s is duplicated in %s: %q and %q", peer.Id, key, firstEntry, entry), fmt.Sprintf("remove %s from %s", entry, key))
+			} else {
+				entriesOfId[peer.Id] = entry
+			}
+			if firstEntry, duplicated := entriesOfAddress[peer.Address()]; duplicated {
+				warnRecord(target, fmt.Sprintf("address %s is duplicated in %s: %q and %q", peer.Address(), key, firstEntry, entry), "one address serves only one node, correct the node ID or the address")
+			} else {
+				entriesOfAddress[peer.Address()] = entry
+			}
+
+			if ip := peer.IP(); ip != nil {
+				if ip.IsUnspecified() {
+					warnRecord(target, fmt.Sprintf("address %s of %q in %s is not dialable", peer.Host, entry, key), "use the public address of the peer")
+				} else if isNonPublicIP(ip) {
+					if !privateNetwork {
+						warnRecord(
+							target,
+							fmt.Sprintf("address %s of %q in %s is a loopback, private or link-local address, not reachable from the internet", peer.Host, entry, key),
+							fmt.Sprintf("use the public address of the peer, or provide --%s if nodes are connected via private network", flagPrivateNetwork),
+						)
+					}
+				}
+			}
+
+			peersOf[key] = append(peersOf[key], peer)
+		}
+	}
+
+	for _, seed := range peersOf["seeds"] {
+		for _, persistentPeer := range peersOf["persistent_peers"] {
+			if seed.Id == persistentPeer.Id && seed.Address() == persistentPeer.Address() {
+				warnRecord(
+					settingTarget("config.toml", configTomlFilePath, "p2p.seeds"),
+					fmt.Sprintf("%s is in both seeds and persistent_peers, seed nodes disconnect after sharing addresses", seed.String()),
+					"remove it from either seeds or persistent_peers",
+				)
+			}
+		}
+	}
+}
+
+// isNonPublicIP returns true for loopback, private and link-local addresses.
+func isNonPublicIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// renderPrometheusTextfile renders check records as metrics in Prometheus text exposition format,
+// to be collected by the textfile collector of node_exporter.
+func renderPrometheusTextfile(home string, nodeType types.NodeType, records []checkRecord, success bool, now time.Time) string {
+	type findingKey struct {
+		severity string
+		rule     string
+	}
+
+	counts := make(map[findingKey]int)
+	for _, record := range records {
+		severity := "warning"
+		if record.fatal {
+			severity = "fatal"
+		}
+		counts[findingKey{severity: severity, rule: record.target.rule}]++
+	}
+
+	keys := make([]findingKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].severity != keys[j].severity {
+			return keys[i].severity < keys[j].severity
+		}
+		return keys[i].rule < keys[j].rule
+	})
+
+	commonLabels := fmt.Sprintf(`home="%s",node_type="%s"`, escapePrometheusLabelValue(home), escapePrometheusLabelValue(nodeType.String()))
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP nodesc_findings Number of findings reported by the last check, by severity and rule.\n")
+	sb.WriteString("# TYPE nodesc_findings gauge\n")
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf(
+			"nodesc_findings{severity=\"%s\",rule=\"%s\",%s} %d\n",
+			key.severity, escapePrometheusLabelValue(key.rule), commonLabels, counts[key],
+		))
+	}
+
+	successValue := 0
+	if success {
+		successValue = 1
+	}
+	sb.WriteString("# HELP nodesc_check_success Whether the last check completed without any finding.\n")
+	sb.WriteString("# TYPE nodesc_check_success gauge\n")
+	sb.WriteString(fmt.Sprintf("nodesc_check_success{%s} %d\n", commonLabels, successValue))
+
+	sb.WriteString("# HELP nodesc_last_run_timestamp Unix timestamp of the last check, in seconds.\n")
+	sb.WriteString("# TYPE nodesc_last_run_timestamp gauge\n")
+	sb.WriteString(fmt.Sprintf("nodesc_last_run_timestamp{%s} %d\n", commonLabels, now.Unix()))
+
+	return sb.String()
+}
+
+func escapePrometheusLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// writeFileAtomically writes to a temporary file in the same directory then renames it,
+// so readers never see a partially written file.
+func writeFileAtomically(filePath string, content []byte, perm os.FileMode) error {
+	dir, fileName := filepath.Split(filePath)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "."+fileName+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpFilePath := tmpFile.Name()
+	defer func() {
+		_ = os.Remove(tmpFilePath) // no-op after renamed
+	}()
+
+	if _, err := tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Chmod(perm); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilePath, filePath)
+}
@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// placeholderPrivValidatorKeyValue is base64 of 64 zero bytes, an ed25519 key which the node can load but never signs with.
+var placeholderPrivValidatorKeyValue = base64.StdEncoding.EncodeToString(make([]byte, 64))
+
+// privateNetworks are ranges which are not routable from the internet.
+var privateNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"10.0.0.0/8",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"100.64.0.0/10", // CGNAT, used by WireGuard/Tailscale
+		"fc00::/7",
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// signerAddress is a parsed tcp:// or unix:// address, tcp address of tmkms may have the node ID prefix tcp://<node ID>@host:port.
+type signerAddress struct {
+	scheme string
+	nodeId string
+	host   string
+	port   string
+}
+
+func parseSignerAddress(addr string) (*signerAddress, error) {
+	scheme, rest, found := strings.Cut(strings.TrimSpace(addr), "://")
+	if !found {
+		return nil, fmt.Errorf("missing scheme, must be tcp:// or unix://")
+	}
+	switch scheme {
+	case "unix":
+		return &signerAddress{scheme: scheme, host: rest}, nil
+	case "tcp":
+		var nodeId string
+		if id, hostPort, found := strings.Cut(rest, "@"); found {
+			if bz, err := hex.DecodeString(id); err != nil || len(bz) != 20 {
+				return nil, fmt.Errorf("invalid node ID %q, must be 40 hex characters", id)
+			}
+			nodeId, rest = id, hostPort
+		}
+		host, port, err := net.SplitHostPort(rest)
+		if err != nil {
+			return nil, err
+		}
+		return &signerAddress{scheme: scheme, nodeId: nodeId, host: host, port: port}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, must be tcp:// or unix://", scheme)
+	}
+}
+
+// isPrivateHost returns if the host is loopback or in a private network, known is false for hostnames.
+func isPrivateHost(host string) (private bool, known bool) {
+	if host == "localhost" {
+		return true, true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false, false
+	}
+	if ip.IsLoopback() {
+		return true, true
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// checkHomeConfigRemoteSigner checks priv_validator_laddr and that no real key is left on disk, for validator using remote signer.
+func checkHomeConfigRemoteSigner(configPath string, configToml *types.ConfigToml) {
+	configTomlFilePath := path.Join(configPath, "config.toml")
+	laddrTarget := settingTarget("config.toml", configTomlFilePath, "priv_validator_laddr")
+	evaluatedRule(laddrTarget)
+
+	if configToml.PrivValidatorLaddr == "" {
+		fatalRecord(laddrTarget, "priv_validator_laddr is not set in config.toml, required by remote signer", "set priv_validator_laddr = \"tcp://<private IP>:26659\" in config.toml")
+	} else if laddr, err := parseSignerAddress(configToml.PrivValidatorLaddr); err != nil {
+		fatalRecord(laddrTarget, fmt.Sprintf("priv_validator_laddr %q in config.toml is invalid: %v", configToml.PrivValidatorLaddr, err), "set priv_validator_laddr = \"tcp://<private IP>:26659\" in config.toml")
+	} else if laddr.scheme == "tcp" {
+		if laddr.host == "" || laddr.host == "0.0.0.0" || laddr.host == "::" {
+			fatalRecord(laddrTarget, fmt.Sprintf("priv_validator_laddr %s in config.toml listens on all interfaces, signer port is exposed publicly", configToml.PrivValidatorLaddr), fmt.Sprintf("bind to the private IP, e.g. tcp://10.0.0.2:%s", laddr.port))
+		} else if private, known := isPrivateHost(laddr.host); !known {
+			warnRecord(laddrTarget, fmt.Sprintf("unable to verify %s in priv_validator_laddr of config.toml is a private interface", laddr.host), "use the private IP instead of hostname")
+		} else if !private {
+			fatalRecord(laddrTarget, fmt.Sprintf("priv_validator_laddr %s in config.toml is bound to a public interface", configToml.PrivValidatorLaddr), fmt.Sprintf("bind to the private IP, e.g. tcp://10.0.0.2:%s", laddr.port))
+		}
+	}
+
+	privValidatorKeyFilePath := path.Join(configPath, "priv_validator_key.json")
+	keyTarget := fileTarget("priv_validator_key.json", privValidatorKeyFilePath, "remote-signer")
+	evaluatedRule(keyTarget)
+
+	_, exists, isDir, err := utils.FileInfo(privValidatorKeyFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check priv_validator_key.json file at %s: %v\n", privValidatorKeyFilePath, err)
+		return
+	}
+	if !exists {
+		fatalRecord(keyTarget, "priv_validator_key.json does not exist, node will generate a new key at startup", "create a placeholder priv_validator_key.json with priv_key value "+placeholderPrivValidatorKeyValue)
+		return
+	}
+	if isDir {
+		exitWithErrorMsgf("ERR: priv_validator_key.json is a directory, it should be a file: %s\n", privValidatorKeyFilePath)
+		return
+	}
+
+	bz, err := os.ReadFile(privValidatorKeyFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read priv_validator_key.json file at %s: %v\n", privValidatorKeyFilePath, err)
+		return
+	}
+	var key struct {
+		PrivKey *struct {
+			Value string `json:"value"`
+		} `json:"priv_key"`
+	}
+	if err := json.Unmarshal(bz, &key); err != nil {
+		exitWithErrorMsgf("ERR: failed to unmarshal priv_validator_key.json file at %s: %v\n", privValidatorKeyFilePath, err)
+		return
+	}
+	if key.PrivKey == nil {
+		return
+	}
+	if !isPlaceholderPrivKey(key.PrivKey.Value) {
+		fatalRecord(
+			keyTarget,
+			"priv_validator_key.json contains a real key while using remote signer, the key can be stolen or used to double sign",
+			fmt.Sprintf("ensure the key is imported into the signer and backed up, then replace value of priv_key with placeholder %s", placeholderPrivValidatorKeyValue),
+		)
+	}
+}
+
+// isPlaceholderPrivKey returns true if the key is empty or all zeros.
+func isPlaceholderPrivKey(value string) bool {
+	bz, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return false
+	}
+	for _, b := range bz {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkRemoteSignerConfig validates tmkms.toml or horcrux config.yaml against the node.
+func checkRemoteSignerConfig(home string, configToml *types.ConfigToml, signerConfigFilePath string) {
+	// invalid priv_validator_laddr is reported by config check
+	laddr, _ := parseSignerAddress(configToml.PrivValidatorLaddr)
+
+	bz, err := os.ReadFile(signerConfigFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read signer config file: %v\n", err)
+		return
+	}
+
+	switch strings.ToLower(filepath.Ext(signerConfigFilePath)) {
+	case ".toml":
+		var tmkms types.TmkmsToml
+		if err := toml.Unmarshal(bz, &tmkms); err != nil {
+			exitWithErrorMsgf("ERR: failed to unmarshal tmkms config file %s: %v\n", signerConfigFilePath, err)
+			return
+		}
+		genesisFilePath := path.Join(home, "config", "genesis.json")
+		chainId, err := utils.ReadGenesisChainId(genesisFilePath)
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to read chain id from genesis: %v\n", err)
+			return
+		}
+		checkTmkmsConfig(signerConfigFilePath, &tmkms, chainId, laddr)
+	case ".yaml", ".yml":
+		var horcrux types.HorcruxConfig
+		if err := yaml.Unmarshal(bz, &horcrux); err != nil {
+			exitWithErrorMsgf("ERR: failed to unmarshal horcrux config file %s: %v\n", signerConfigFilePath, err)
+			return
+		}
+		checkHorcruxConfig(signerConfigFilePath, &horcrux, laddr)
+	default:
+		exitWithErrorMsgf("ERR: unknown signer config file %s, must be tmkms.toml or horcrux config.yaml\n", signerConfigFilePath)
+	}
+}
+
+func checkTmkmsConfig(filePath string, tmkms *types.TmkmsToml, chainId string, laddr *signerAddress) {
+	tmkmsTarget := func(rule string) recordTarget {
+		return fileTarget("tmkms", filePath, rule)
+	}
+
+	evaluatedRule(tmkmsTarget("chain"), tmkmsTarget("state-file"), tmkmsTarget("provider"), tmkmsTarget("validator"))
+	var chain *types.TmkmsChain
+	for i, c := range tmkms.Chain {
+		if c.Id == chainId {
+			chain = &tmkms.Chain[i]
+			break
+		}
+	}
+	if chain == nil {
+		fatalRecord(tmkmsTarget("chain"), fmt.Sprintf("tmkms config has no [[chain]] with id %s of the genesis", chainId), fmt.Sprintf("add [[chain]] section with id = \"%s\"", chainId))
+	} else if chain.StateFile == "" {
+		fatalRecord(tmkmsTarget("state-file"), fmt.Sprintf("state_file is missing in [[chain]] %s of tmkms config, double sign protection relies on it", chainId), "set state_file of the chain")
+	}
+
+	var hasProvider bool
+	for _, providers := range tmkms.Providers {
+		for _, provider := range providers {
+			for _, providerChainId := range provider.ChainIds {
+				if providerChainId == chainId {
+					hasProvider = true
+				}
+			}
+		}
+	}
+	if !hasProvider {
+		fatalRecord(tmkmsTarget("provider"), fmt.Sprintf("no key provider in [providers] of tmkms config serves chain %s", chainId), fmt.Sprintf("add chain_ids = [\"%s\"] to the provider, e.g. [[providers.softsign]]", chainId))
+	}
+
+	var validator *types.TmkmsValidator
+	for i, v := range tmkms.Validator {
+		if v.ChainId == chainId {
+			validator = &tmkms.Validator[i]
+			break
+		}
+	}
+	if validator == nil {
+		fatalRecord(tmkmsTarget("validator"), fmt.Sprintf("tmkms config has no [[validator]] with chain_id %s", chainId), "add [[validator]] section pointing to priv_validator_laddr of the node")
+		return
+	}
+	evaluatedRule(tmkmsTarget("secret-key"), tmkmsTarget("protocol-version"), tmkmsTarget("addr"))
+	if validator.SecretKey == "" {
+		fatalRecord(tmkmsTarget("secret-key"), "secret_key is missing in [[validator]] of tmkms config, required to establish connection to the node", "generate by tmkms init, then set secret_key")
+	}
+	if validator.ProtocolVersion == "" {
+		warnRecord(tmkmsTarget("protocol-version"), "protocol_version is missing in [[validator]] of tmkms config", "set protocol_version matches the CometBFT version of the node, e.g. \"v0.34\"")
+	}
+
+	addr, err := parseSignerAddress(validator.Addr)
+	if err != nil {
+		fatalRecord(tmkmsTarget("addr"), fmt.Sprintf("addr %q in [[validator]] of tmkms config is invalid: %v", validator.Addr, err), "")
+		return
+	}
+	checkSignerAddressMatches(tmkmsTarget("addr"), "addr in [[validator]] of tmkms config", addr, laddr)
+}
+
+func checkHorcruxConfig(filePath string, horcrux *types.HorcruxConfig, laddr *signerAddress) {
+	horcruxTarget := func(rule string) recordTarget {
+		return fileTarget("horcrux", filePath, rule)
+	}
+
+	evaluatedRule(horcruxTarget("sign-mode"))
+	switch horcrux.SignMode {
+	case "threshold":
+		evaluatedRule(horcruxTarget("threshold-mode"))
+		if horcrux.ThresholdMode == nil {
+			fatalRecord(horcruxTarget("threshold-mode"), "thresholdMode is missing in horcrux config", "")
+			break
+		}
+		evaluatedRule(horcruxTarget("cosigners"), horcruxTarget("threshold"), horcruxTarget("shard-id"), horcruxTarget("p2p-addr"))
+		cosigners := len(horcrux.ThresholdMode.Cosigners)
+		threshold := horcrux.ThresholdMode.Threshold
+		if cosigners < 2 {
+			fatalRecord(horcruxTarget("cosigners"), fmt.Sprintf("horcrux config has %d cosigner, threshold signing requires at least 2", cosigners), "")
+		}
+		if threshold < 2 || threshold > cosigners || threshold <= cosigners/2 {
+			fatalRecord(
+				horcruxTarget("threshold"),
+				fmt.Sprintf("threshold %d is invalid for %d cosigners, must be greater than half and at most the number of cosigners", threshold, cosigners),
+				fmt.Sprintf("set threshold to %d", cosigners/2+1),
+			)
+		}
+		shardIds := make(map[int]bool)
+		p2pAddrs := make(map[string]bool)
+		for _, cosigner := range horcrux.ThresholdMode.Cosigners {
+			if cosigner.ShardId < 1 || cosigner.ShardId > cosigners || shardIds[cosigner.ShardId] {
+				fatalRecord(horcruxTarget("shard-id"), fmt.Sprintf("shardID %d of cosigner is invalid or duplicated, must be unique from 1 to %d", cosigner.ShardId, cosigners), "")
+			}
+			shardIds[cosigner.ShardId] = true
+			if cosigner.P2pAddr == "" || p2pAddrs[cosigner.P2pAddr] {
+				fatalRecord(horcruxTarget("p2p-addr"), fmt.Sprintf("p2pAddr %q of cosigner %d is missing or duplicated", cosigner.P2pAddr, cosigner.ShardId), "")
+			}
+			p2pAddrs[cosigner.P2pAddr] = true
+		}
+	case "single":
+		warnRecord(horcruxTarget("sign-mode"), "horcrux is in single sign mode, there is no fault tolerance of the signer", "use threshold sign mode")
+	default:
+		fatalRecord(horcruxTarget("sign-mode"), fmt.Sprintf("signMode %q is invalid in horcrux config, must be threshold or single", horcrux.SignMode), "")
+	}
+
+	if laddr == nil {
+		return
+	}
+	evaluatedRule(horcruxTarget("chain-nodes"))
+	var matched bool
+	var unverifiedAddr *signerAddress
+	for _, chainNode := range horcrux.ChainNodes {
+		addr, err := parseSignerAddress(chainNode.PrivValAddr)
+		if err != nil {
+			continue
+		}
+		if same, verified := isSameSignerAddress(addr, laddr); same && verified {
+			matched = true
+			break
+		} else if same {
+			unverifiedAddr = addr
+		}
+	}
+	if !matched && unverifiedAddr != nil {
+		warnRecord(
+			horcruxTarget("chain-nodes"),
+			fmt.Sprintf("unable to verify privValAddr %s in chainNodes of horcrux config is priv_validator_laddr %s of the node", unverifiedAddr.String(), laddr.String()),
+			"use the same private IP in both places",
+		)
+	} else if !matched {
+		fatalRecord(
+			horcruxTarget("chain-nodes"),
+			fmt.Sprintf("no privValAddr in chainNodes of horcrux config points to priv_validator_laddr %s of the node", laddr.String()),
+			"add the node to chainNodes",
+		)
+	}
+}
+
+// checkSignerAddressMatches checks that the address the signer dials is the one the node listens on.
+func checkSignerAddressMatches(target recordTarget, name string, addr *signerAddress, laddr *signerAddress) {
+	if laddr == nil {
+		return
+	}
+	same, verified := isSameSignerAddress(addr, laddr)
+	if !same {
+		fatalRecord(
+			target,
+			fmt.Sprintf("%s %s does not point to priv_validator_laddr %s of the node", name, addr.String(), laddr.String()),
+			"",
+		)
+	} else if !verified {
+		warnRecord(
+			target,
+			fmt.Sprintf("unable to verify host %s of %s is the host %s of priv_validator_laddr", addr.host, name, laddr.host),
+			"use the same private IP in both places",
+		)
+	}
+}
+
+// isSameSignerAddress compares the address dialed by the signer with priv_validator_laddr.
+// IPs are compared as IPs and hostnames as strings, verified is false when one is a hostname and the other an IP.
+// Any host matches when the node listens on all interfaces.
+func isSameSignerAddress(addr *signerAddress, laddr *signerAddress) (same bool, verified bool) {
+	if addr.scheme != laddr.scheme {
+		return false, true
+	}
+	if addr.scheme == "unix" {
+		return addr.host == laddr.host, true
+	}
+	if addr.port != laddr.port {
+		return false, true
+	}
+	addrIp, laddrIp := net.ParseIP(addr.host), net.ParseIP(laddr.host)
+	if laddr.host == "" || (laddrIp != nil && laddrIp.IsUnspecified()) {
+		return true, true
+	}
+	if addrIp != nil && laddrIp != nil {
+		return addrIp.Equal(laddrIp), true
+	}
+	if addrIp == nil && laddrIp == nil {
+		return strings.EqualFold(addr.host, laddr.host), true
+	}
+	return true, false
+}
+
+func (a *signerAddress) String() string {
+	if a.scheme == "unix" {
+		return "unix://" + a.host
+	}
+	if a.nodeId != "" {
+		return "tcp://" + a.nodeId + "@" + net.JoinHostPort(a.host, a.port)
+	}
+	return "tcp://" + net.JoinHostPort(a.host, a.port)
+}
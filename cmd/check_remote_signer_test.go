@@ -0,0 +1,75 @@
+package cmd
+
+import "testing"
+
+func TestParseSignerAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    signerAddress
+		wantErr bool
+	}{
+		{addr: "tcp://10.0.0.2:26659", want: signerAddress{scheme: "tcp", host: "10.0.0.2", port: "26659"}},
+		{addr: "tcp://f88883b673fc69d7869cab098de3bafc2ff76eb8@10.0.0.2:26659", want: signerAddress{scheme: "tcp", nodeId: "f88883b673fc69d7869cab098de3bafc2ff76eb8", host: "10.0.0.2", port: "26659"}},
+		{addr: "tcp://[fd00::2]:26659", want: signerAddress{scheme: "tcp", host: "fd00::2", port: "26659"}},
+		{addr: "unix:///run/tmkms.sock", want: signerAddress{scheme: "unix", host: "/run/tmkms.sock"}},
+		{addr: "tcp://abc@10.0.0.2:26659", wantErr: true},
+		{addr: "tcp://f88883b673fc69d7869cab098de3bafc2ff76eb8@10.0.0.2", wantErr: true},
+		{addr: "10.0.0.2:26659", wantErr: true},
+		{addr: "udp://10.0.0.2:26659", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := parseSignerAddress(tt.addr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSignerAddress(%q) = %+v, want error", tt.addr, *got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSignerAddress(%q) failed: %v", tt.addr, err)
+			}
+			if *got != tt.want {
+				t.Errorf("parseSignerAddress(%q) = %+v, want %+v", tt.addr, *got, tt.want)
+			}
+			if got.String() != tt.addr {
+				t.Errorf("String() = %s, want %s", got.String(), tt.addr)
+			}
+		})
+	}
+}
+
+func TestIsSameSignerAddress(t *testing.T) {
+	tests := []struct {
+		addr         string
+		laddr        string
+		wantSame     bool
+		wantVerified bool
+	}{
+		{addr: "tcp://10.0.0.2:26659", laddr: "tcp://10.0.0.2:26659", wantSame: true, wantVerified: true},
+		{addr: "tcp://f88883b673fc69d7869cab098de3bafc2ff76eb8@10.0.0.2:26659", laddr: "tcp://10.0.0.2:26659", wantSame: true, wantVerified: true},
+		{addr: "tcp://f88883b673fc69d7869cab098de3bafc2ff76eb8@10.0.0.3:26659", laddr: "tcp://10.0.0.2:26659", wantSame: false, wantVerified: true},
+		{addr: "tcp://10.0.0.2:26660", laddr: "tcp://10.0.0.2:26659", wantSame: false, wantVerified: true},
+		{addr: "tcp://10.0.0.3:26659", laddr: "tcp://0.0.0.0:26659", wantSame: true, wantVerified: true},
+		{addr: "tcp://validator.internal:26659", laddr: "tcp://validator.internal:26659", wantSame: true, wantVerified: true},
+		{addr: "tcp://sentry.internal:26659", laddr: "tcp://validator.internal:26659", wantSame: false, wantVerified: true},
+		{addr: "tcp://validator.internal:26659", laddr: "tcp://10.0.0.2:26659", wantSame: true, wantVerified: false},
+		{addr: "unix:///run/tmkms.sock", laddr: "tcp://10.0.0.2:26659", wantSame: false, wantVerified: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr+" "+tt.laddr, func(t *testing.T) {
+			addr, err := parseSignerAddress(tt.addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			laddr, err := parseSignerAddress(tt.laddr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			same, verified := isSameSignerAddress(addr, laddr)
+			if same != tt.wantSame || verified != tt.wantVerified {
+				t.Errorf("isSameSignerAddress() = (%t, %t), want (%t, %t)", same, verified, tt.wantSame, tt.wantVerified)
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@ import (
 )
 
 func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
+	serviceSetting := func(key string) recordTarget {
+		return settingTarget("service", serviceFilePath, key)
+	}
+
 	perm, exists, isDir, err := utils.FileInfo(serviceFilePath)
 	if err != nil {
 		exitWithErrorMsgf("ERR: failed to check service file at %s: %v\n", serviceFilePath, err)
@@ -24,14 +28,19 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 		exitWithErrorMsgf("ERR: service file is a directory, it should be a file: %s\n", serviceFilePath)
 		return
 	}
+	evaluatedRule(
+		fileTarget("service", serviceFilePath, "permission"),
+		fileTarget("service", serviceFilePath, "extension"),
+		fileTarget("service", serviceFilePath, "location"),
+	)
 	if perm != 0o644 {
-		fatalRecord("service file has invalid permission", "sudo chmod 644 "+serviceFilePath)
+		fatalRecord(fileTarget("service", serviceFilePath, "permission"), "service file has invalid permission", "sudo chmod 644 "+serviceFilePath)
 	}
 	if !strings.HasSuffix(serviceFilePath, ".service") {
-		fatalRecord("service file is not a systemd service file", "use .service file extension")
+		fatalRecord(fileTarget("service", serviceFilePath, "extension"), "service file is not a systemd service file", "use .service file extension")
 	}
 	if !strings.HasPrefix(serviceFilePath, "/etc/systemd/system") {
-		warnRecord("service file is not in /etc/systemd/system directory", "use systemd")
+		warnRecord(fileTarget("service", serviceFilePath, "location"), "service file is not in /etc/systemd/system directory", "use systemd")
 	}
 
 	// check service file content
@@ -49,33 +58,46 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 		return
 	}
 
+	evaluatedRule(
+		serviceSetting("Unit.Description"),
+		serviceSetting("Unit.After"),
+		serviceSetting("Service.User"),
+		serviceSetting("Service.ExecStart"),
+		serviceSetting("Service.Restart"),
+		serviceSetting("Service.RestartSec"),
+		serviceSetting("Install.WantedBy"),
+		fileTarget("service", serviceFilePath, "enabled"),
+	)
+
 	originalRecordsCount := len(checkRecords)
 	defer func() {
 		if len(checkRecords) > originalRecordsCount {
-			warnRecord("remember to reload service after updated service file", "sudo systemctl daemon-reload")
+			warnRecord(fileTarget("service", serviceFilePath, "daemon-reload"), "remember to reload service after updated service file", "sudo systemctl daemon-reload")
 		}
 	}()
 
 	if sf.Unit.Description.String() == "" {
-		fatalRecord("service file is missing Description in [Unit] section", "add Description to [Unit] section")
+		fatalRecord(serviceSetting("Unit.Description"), "service file is missing Description in [Unit] section", "add Description to [Unit] section")
 	}
 	if sf.Unit.After.String() == "" {
-		fatalRecord("service file is missing After in [Unit] section", "add After to [Unit] section")
+		fatalRecord(serviceSetting("Unit.After"), "service file is missing After in [Unit] section", "add After to [Unit] section")
 	} else if sf.Unit.After.String() != "network-online.target" {
-		fatalRecord("service file is using invalid After in [Unit] section", "change After to network-online.target")
+		fatalRecord(serviceSetting("Unit.After"), "service file is using invalid After in [Unit] section", "change After to network-online.target")
 	}
 
 	if sf.Service.User.String() == "" {
-		fatalRecord("service file is missing User in [Service] section", "add User to [Service] section")
+		fatalRecord(serviceSetting("Service.User"), "service file is missing User in [Service] section", "add User to [Service] section")
 	} else {
 		user := strings.TrimSpace(strings.ToLower(sf.Service.User.String()))
 		if user == "root" || user == "ubuntu" {
 			fatalRecord(
+				serviceSetting("Service.User"),
 				"service file is using invalid User in [Service] section",
 				"change User to a non-root user",
 			)
 		} else if !strings.Contains(user, "-") {
 			warnRecord(
+				serviceSetting("Service.User"),
 				"service file is using invalid User in [Service] section",
 				"use memorable username with hyphen, e.g. \"val-x-testnet\"",
 			)
@@ -83,10 +105,12 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 	}
 	if sf.Service.ExecStart.String() == "" {
 		fatalRecord(
+			serviceSetting("Service.ExecStart"),
 			"service file is missing ExecStart in [Service] section", "add ExecStart to [Service] section",
 		)
 	} else if !strings.Contains(sf.Service.ExecStart.String(), "--home") {
 		fatalRecord(
+			serviceSetting("Service.ExecStart"),
 			"service file is missing --home in ExecStart in [Service] section",
 			"add --home to ExecStart in [Service] section",
 		)
@@ -94,6 +118,7 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 		_, homeName := filepath.Split(home)
 		if !strings.Contains(sf.Service.ExecStart.String(), homeName) {
 			fatalRecord(
+				serviceSetting("Service.ExecStart"),
 				fmt.Sprintf("--home in ExecStart in [Service] section might not pointing to the correct home dir \"%s\"", homeName),
 				"change --home to --home="+homeName,
 			)
@@ -101,17 +126,20 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 	}
 	if sf.Service.Restart.String() == "" {
 		fatalRecord(
+			serviceSetting("Service.Restart"),
 			"service file is missing Restart in [Service] section",
 			"add Restart=no to [Service] section",
 		)
 	} else if sf.Service.Restart.String() != "no" {
 		fatalRecord(
+			serviceSetting("Service.Restart"),
 			"service file is using invalid Restart in [Service] section, must using 'no' to prevent incident restart",
 			"change Restart=no",
 		)
 	}
 	if sf.Service.RestartSec.String() != "" {
 		fatalRecord(
+			serviceSetting("Service.RestartSec"),
 			"service file contains RestartSec in [Service] section",
 			"remove RestartSec from [Service] section",
 		)
@@ -119,11 +147,13 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 
 	if sf.Install.WantedBy.String() == "" {
 		fatalRecord(
+			serviceSetting("Install.WantedBy"),
 			"service file is missing WantedBy in [Install] section",
 			"add WantedBy=multi-user.target in [Install] section",
 		)
 	} else if sf.Install.WantedBy.String() != "multi-user.target" {
 		fatalRecord(
+			serviceSetting("Install.WantedBy"),
 			"service file is using invalid WantedBy in [Install] section",
 			"change WantedBy to multi-user.target in [Install] section",
 		)
@@ -138,6 +168,7 @@ func checkServiceFileForValidatorOnLinux(home string, serviceFilePath string) {
 	}
 	if exists {
 		fatalRecord(
+			fileTarget("service", serviceFilePath, "enabled"),
 			"service file is already enabled, validator must disable service automatically run at startup",
 			"sudo systemctl disable "+serviceFileName,
 		)
@@ -2,22 +2,72 @@ package cmd
 
 type checkRecord struct {
 	fatal   bool
+	target  recordTarget
 	message string
 	suggest string
 	addedNo int
 }
 
+// recordTarget identifies the rule which produced a check record and where the offending setting is.
+type recordTarget struct {
+	rule string // stable identifier of the rule, e.g. "app.toml/pruning"
+	file string // path of the file or directory the record is about, optional
+	key  string // dotted key of the setting within the file, e.g. "grpc.enable", used to locate the line
+	line int    // 1-based line of the offending setting, when already known, e.g. files which are not TOML/INI/JSON
+}
+
+// settingTarget targets a setting within a config file, the key is used as part of the rule identifier.
+func settingTarget(kind, file, key string) recordTarget {
+	return recordTarget{rule: kind + "/" + key, file: file, key: key}
+}
+
+// fileTarget targets the file or directory itself, like permission.
+func fileTarget(kind, file, rule string) recordTarget {
+	return recordTarget{rule: kind + "/" + rule, file: file}
+}
+
+// lineTarget targets a known line within a file.
+func lineTarget(kind, file, rule string, line int) recordTarget {
+	return recordTarget{rule: kind + "/" + rule, file: file, line: line}
+}
+
+// ruleTarget targets a rule which is not related to any file.
+func ruleTarget(kind, rule string) recordTarget {
+	return recordTarget{rule: kind + "/" + rule}
+}
+
 var checkRecords []checkRecord
 
+// evaluatedRules are rules which have been evaluated in order, passed or not, used by reports listing every rule like JUnit.
+var evaluatedRules []string
+
+// evaluatedRule marks rules of the targets as evaluated, call it where the check of the rule is done,
+// rules producing records are marked automatically.
+func evaluatedRule(targets ...recordTarget) {
+	for _, target := range targets {
+		found := false
+		for _, rule := range evaluatedRules {
+			if rule == target.rule {
+				found = true
+				break
+			}
+		}
+		if !found {
+			evaluatedRules = append(evaluatedRules, target.rule)
+		}
+	}
+}
+
 func putCheckRecord(record checkRecord) {
+	evaluatedRule(record.target)
 	record.addedNo = len(checkRecords) + 1
 	checkRecords = append(checkRecords, record)
 }
 
-func fatalRecord(message string, suggest string) {
-	putCheckRecord(checkRecord{fatal: true, message: message, suggest: suggest})
+func fatalRecord(target recordTarget, message string, suggest string) {
+	putCheckRecord(checkRecord{fatal: true, target: target, message: message, suggest: suggest})
 }
 
-func warnRecord(message string, suggest string) {
-	putCheckRecord(checkRecord{fatal: false, message: message, suggest: suggest})
+func warnRecord(target recordTarget, message string, suggest string) {
+	putCheckRecord(checkRecord{fatal: false, target: target, message: message, suggest: suggest})
 }
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxClockOffset is the maximum clock offset from NTP time, larger offset causes missed blocks and proposal timeouts.
+const maxClockOffset = 100 * time.Millisecond
+
+// timeSyncDaemon is a NTP daemon which can be detected from config files, relative to /etc.
+type timeSyncDaemon struct {
+	name        string
+	units       []string
+	configFiles []string // glob patterns
+	readServers func(filePath string, etcRoot string) ([]string, error)
+}
+
+var timeSyncDaemons = []timeSyncDaemon{
+	{
+		name:        "chrony",
+		units:       []string{"chrony", "chronyd"},
+		configFiles: []string{"chrony/chrony.conf", "chrony.conf"},
+		readServers: readNtpConfServers,
+	},
+	{
+		name:        "systemd-timesyncd",
+		units:       []string{"systemd-timesyncd"},
+		configFiles: []string{"systemd/timesyncd.conf", "systemd/timesyncd.conf.d/*.conf"},
+		readServers: readTimesyncdConfServers,
+	},
+	{
+		name:        "ntpd",
+		units:       []string{"ntp", "ntpd", "ntpsec"},
+		configFiles: []string{"ntp.conf", "ntpsec/ntp.conf"},
+		readServers: readNtpConfServers,
+	},
+}
+
+// checkTimeSync checks that the clock is synchronized by a NTP daemon.
+// Daemons are detected from config files and unit enablement under etcRoot.
+// If statusFilePath is provided, the synchronization status & offset are checked as well.
+func checkTimeSync(nodeType types.NodeType, etcRoot string, statusFilePath string) {
+	timeSyncRecord := warnRecord
+	if nodeType == types.ValidatorNode {
+		timeSyncRecord = fatalRecord
+	}
+
+	systemDir := filepath.Join(etcRoot, "systemd", "system")
+
+	evaluatedRule(ruleTarget("time-sync", "daemon"))
+	var enabledDaemons []string
+	for _, daemon := range timeSyncDaemons {
+		var enabledUnit string
+		for _, unitName := range daemon.units {
+			enabled, err := isSystemdUnitEnabled(systemDir, unitName)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to check if %s is enabled: %v\n", unitName, err)
+				return
+			}
+			if enabled {
+				enabledUnit = unitName
+				break
+			}
+		}
+		if enabledUnit == "" {
+			continue
+		}
+		enabledDaemons = append(enabledDaemons, daemon.name)
+		evaluatedRule(ruleTarget("time-sync", "servers"))
+
+		var configFiles []string
+		for _, pattern := range daemon.configFiles {
+			matches, err := filepath.Glob(filepath.Join(etcRoot, pattern))
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to find config files of %s: %v\n", daemon.name, err)
+				return
+			}
+			configFiles = append(configFiles, matches...)
+		}
+
+		var servers []string
+		for _, configFile := range configFiles {
+			found, err := daemon.readServers(configFile, etcRoot)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to read config file of %s at %s: %v\n", daemon.name, configFile, err)
+				return
+			}
+			servers = append(servers, found...)
+		}
+
+		if len(servers) > 0 {
+			continue
+		}
+		if daemon.name == "systemd-timesyncd" {
+			// falls back to the servers compiled in by the distribution
+			warnRecord(
+				ruleTarget("time-sync", "servers"),
+				"no NTP server is configured for systemd-timesyncd, relying on the fallback servers of the distribution",
+				fmt.Sprintf("set NTP= in [Time] section of %s", filepath.Join(etcRoot, "systemd", "timesyncd.conf")),
+			)
+			continue
+		}
+		target := ruleTarget("time-sync", "servers")
+		if len(configFiles) > 0 {
+			target = fileTarget("time-sync", configFiles[0], "servers")
+		}
+		timeSyncRecord(
+			target,
+			fmt.Sprintf("%s is enabled but no NTP server is configured in %s and the files it includes", daemon.name, strings.Join(configFiles, ", ")),
+			"add server or pool to the config file, then restart "+enabledUnit,
+		)
+	}
+
+	if len(enabledDaemons) == 0 {
+		timeSyncRecord(
+			ruleTarget("time-sync", "daemon"),
+			"no time synchronization daemon is enabled (chrony, systemd-timesyncd, ntpd), clock drift causes missed blocks",
+			"sudo apt install chrony && sudo systemctl enable --now chrony",
+		)
+	} else if len(enabledDaemons) > 1 {
+		warnRecord(
+			ruleTarget("time-sync", "daemon"),
+			fmt.Sprintf("multiple time synchronization daemons are enabled: %s, they will fight over the clock", strings.Join(enabledDaemons, ", ")),
+			"keep only one of them enabled",
+		)
+	}
+
+	if statusFilePath == "" {
+		return
+	}
+
+	bz, err := os.ReadFile(statusFilePath)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read time sync status file: %v\n", err)
+		return
+	}
+	status, err := utils.ParseTimeSyncStatus(string(bz))
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to parse time sync status file %s: %v\n", statusFilePath, err)
+		return
+	}
+
+	evaluatedRule(fileTarget("time-sync", statusFilePath, "synchronized"), fileTarget("time-sync", statusFilePath, "offset"))
+	if status.Synchronized != nil && !*status.Synchronized {
+		timeSyncRecord(
+			fileTarget("time-sync", statusFilePath, "synchronized"),
+			"clock is not synchronized with NTP",
+			"check status of the time synchronization daemon",
+		)
+	}
+	if status.Offset != nil {
+		offset := *status.Offset
+		if offset < 0 {
+			offset = -offset
+		}
+		if offset > maxClockOffset {
+			timeSyncRecord(
+				fileTarget("time-sync", statusFilePath, "offset"),
+				fmt.Sprintf("clock offset from NTP time is %s, higher than %s", status.Offset.String(), maxClockOffset),
+				"check reachability of the NTP servers, consider using chrony with multiple servers",
+			)
+		}
+	}
+}
+
+// readNtpConfServers reads server, pool and peer directives of chrony.conf or ntp.conf,
+// following include, confdir & sourcedir of chrony and includefile of ntpd, e.g. servers of Debian chrony are in /etc/chrony/sources.d.
+// Relative paths are relative to the directory of the file, absolute paths under /etc are resolved under etcRoot.
+func readNtpConfServers(filePath string, etcRoot string) ([]string, error) {
+	return readNtpConfServersAt(filePath, etcRoot, 0)
+}
+
+func readNtpConfServersAt(filePath string, etcRoot string, depth int) ([]string, error) {
+	if depth > 16 {
+		return nil, fmt.Errorf("too deep include at %s", filePath)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var servers []string
+	var includePatterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "server", "pool", "peer":
+			servers = append(servers, fields[1])
+		case "include", "includefile":
+			includePatterns = append(includePatterns, fields[1])
+		case "confdir":
+			for _, dir := range fields[1:] {
+				includePatterns = append(includePatterns, filepath.Join(dir, "*.conf"))
+			}
+		case "sourcedir":
+			for _, dir := range fields[1:] {
+				includePatterns = append(includePatterns, filepath.Join(dir, "*.sources"))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range includePatterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(filePath), pattern)
+		} else if strings.HasPrefix(pattern, "/etc/") {
+			pattern = filepath.Join(etcRoot, strings.TrimPrefix(pattern, "/etc/"))
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q at %s", pattern, filePath)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			found, err := readNtpConfServersAt(match, etcRoot, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, found...)
+		}
+	}
+	return servers, nil
+}
+
+// readTimesyncdConfServers reads NTP= and FallbackNTP= of timesyncd.conf, servers are space-separated.
+func readTimesyncdConfServers(filePath string, _ string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "NTP", "FallbackNTP":
+			servers = append(servers, strings.Fields(value)...)
+		}
+	}
+	return servers, scanner.Err()
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"github.com/bcdevtools/node-setup-check/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEtcFiles writes the files under a temporary etc root, keys are paths relative to /etc.
+func writeEtcFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	etcRoot := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(etcRoot, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return etcRoot
+}
+
+func TestReadNtpConfServers(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		main  string
+		want  []string
+	}{
+		{
+			name: "debian chrony",
+			files: map[string]string{
+				"chrony/chrony.conf":              "confdir /etc/chrony/conf.d\nsourcedir /run/chrony-dhcp\nsourcedir /etc/chrony/sources.d\nkeyfile /etc/chrony/chrony.keys\n",
+				"chrony/conf.d/local.conf":        "makestep 1 3\n",
+				"chrony/sources.d/ubuntu.sources": "pool ntp.ubuntu.com iburst maxsources 4\n",
+				"chrony/sources.d/ignored.conf":   "server ignored.example.com\n",
+			},
+			main: "chrony/chrony.conf",
+			want: []string{"ntp.ubuntu.com"},
+		},
+		{
+			name: "chrony include",
+			files: map[string]string{
+				"chrony.conf":     "server a.example.com iburst\ninclude /etc/chrony.d/*.conf\ninclude extra.conf\n",
+				"chrony.d/b.conf": "pool b.example.com\n",
+				"chrony.d/c.conf": "server c.example.com\n",
+				"extra.conf":      "peer d.example.com\n",
+			},
+			main: "chrony.conf",
+			want: []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"},
+		},
+		{
+			name: "ntp includefile",
+			files: map[string]string{
+				"ntp.conf":         "driftfile /var/lib/ntp/ntp.drift\nincludefile /etc/ntp/servers.conf\n",
+				"ntp/servers.conf": "pool 0.pool.ntp.org iburst\n",
+			},
+			main: "ntp.conf",
+			want: []string{"0.pool.ntp.org"},
+		},
+		{
+			name: "sources not included",
+			files: map[string]string{
+				"chrony/chrony.conf":              "keyfile /etc/chrony/chrony.keys\n",
+				"chrony/sources.d/ubuntu.sources": "pool ntp.ubuntu.com iburst\n",
+			},
+			main: "chrony/chrony.conf",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etcRoot := writeEtcFiles(t, tt.files)
+
+			got, err := readNtpConfServers(filepath.Join(etcRoot, tt.main), etcRoot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("servers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadNtpConfServers_IncludeLoop(t *testing.T) {
+	etcRoot := writeEtcFiles(t, map[string]string{
+		"chrony.conf": "include /etc/chrony.conf\n",
+	})
+	if _, err := readNtpConfServers(filepath.Join(etcRoot, "chrony.conf"), etcRoot); err == nil {
+		t.Fatal("include loop should fail")
+	}
+}
+
+func TestCheckTimeSync_DebianChrony(t *testing.T) {
+	resetCheckRecords(t)
+
+	etcRoot := writeEtcFiles(t, map[string]string{
+		"systemd/system/multi-user.target.wants/chrony.service": "",
+		"chrony/chrony.conf":              "confdir /etc/chrony/conf.d\nsourcedir /etc/chrony/sources.d\n",
+		"chrony/sources.d/ubuntu.sources": "pool ntp.ubuntu.com iburst maxsources 4\n",
+	})
+
+	checkTimeSync(types.ValidatorNode, etcRoot, "")
+
+	if len(checkRecords) > 0 {
+		t.Fatalf("want no record, got %s: %s", checkRecords[0].target.rule, checkRecords[0].message)
+	}
+}
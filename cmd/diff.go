@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	flagSameValidatorKey = "same-validator-key"
+	flagSameNodeKey      = "same-node-key"
+)
+
+// settingsExpectedToDiffer are settings which are unique per node, differences are expected.
+var settingsExpectedToDiffer = map[string]bool{
+	"config.toml:moniker":              true,
+	"config.toml:p2p.external_address": true,
+	"config.toml:p2p.laddr":            true,
+	"config.toml:rpc.laddr":            true,
+	"config.toml:proxy_app":            true,
+	"app.toml:api.address":             true,
+	"app.toml:grpc.address":            true,
+	"app.toml:json-rpc.address":        true,
+	"app.toml:json-rpc.ws-address":     true,
+}
+
+// extraSettingsMatterPerNodeType are settings which are not inspected by the check but matter for the node type.
+var extraSettingsMatterPerNodeType = map[types.NodeType][]string{
+	types.ValidatorNode: {
+		"config.toml:priv_validator_laddr",
+		"config.toml:p2p.pex",
+		"config.toml:p2p.private_peer_ids",
+		"config.toml:p2p.unconditional_peer_ids",
+		"config.toml:consensus.timeout_commit",
+		"config.toml:db_backend",
+		"app.toml:app-db-backend",
+		"client.toml:chain-id",
+	},
+	types.RpcNode: {
+		"config.toml:rpc.cors_allowed_origins",
+		"config.toml:rpc.max_open_connections",
+		"config.toml:rpc.max_subscription_clients",
+		"app.toml:api.max-open-connections",
+		"app.toml:json-rpc.api",
+		"app.toml:json-rpc.gas-cap",
+		"config.toml:db_backend",
+		"app.toml:app-db-backend",
+		"client.toml:chain-id",
+	},
+	types.SnapshotNode: {
+		"config.toml:db_backend",
+		"app.toml:app-db-backend",
+		"client.toml:chain-id",
+	},
+	types.ArchivalNode: {
+		"config.toml:rpc.cors_allowed_origins",
+		"app.toml:json-rpc.api",
+		"config.toml:db_backend",
+		"app.toml:app-db-backend",
+		"client.toml:chain-id",
+	},
+}
+
+func GetDiffCmd() *cobra.Command {
+	validTargetValues := strings.Join(types.AllNodeTypeNames(), "/")
+
+	var cmd = &cobra.Command{
+		Use:   "diff [homeA] [homeB]",
+		Short: "Compare settings, genesis and keys of two node homes",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			homeA, homeB := args[0], args[1]
+
+			nodeType := types.UnspecifiedNodeType
+			typeName, _ := cmd.Flags().GetString(flagType)
+			if typeName != "" {
+				nodeType = types.NodeTypeFromString(typeName)
+				if nodeType == types.UnspecifiedNodeType {
+					exitWithErrorMsgf("ERR: Invalid node type, can be either %s\n", validTargetValues)
+					return
+				}
+			}
+			expectSameValidatorKey, _ := cmd.Flags().GetBool(flagSameValidatorKey)
+			expectSameNodeKey, _ := cmd.Flags().GetBool(flagSameNodeKey)
+
+			mattered := settingsMatterForNodeType(nodeType)
+
+			var countProblems int
+
+			fmt.Printf("Comparing\n A: %s\n B: %s\n", homeA, homeB)
+
+			for _, fileName := range []string{"app.toml", "config.toml", "client.toml"} {
+				settingsA, err := readTomlSettings(path.Join(homeA, "config", fileName))
+				if err != nil {
+					exitWithErrorMsgf("ERR: failed to read %s of A: %v\n", fileName, err)
+					return
+				}
+				settingsB, err := readTomlSettings(path.Join(homeB, "config", fileName))
+				if err != nil {
+					exitWithErrorMsgf("ERR: failed to read %s of B: %v\n", fileName, err)
+					return
+				}
+
+				fmt.Printf("\n%s:\n", fileName)
+				var countDiff int
+				for _, key := range sortedKeys(unionKeys(settingsA, settingsB)) {
+					valueA, foundA := settingsA[key]
+					valueB, foundB := settingsB[key]
+					if foundA && foundB && valueA == valueB {
+						continue
+					}
+
+					countDiff++
+					formatValue := func(value string, found bool) string {
+						if !found {
+							return "(missing)"
+						}
+						return fmt.Sprintf("%q", value)
+					}
+
+					settingKey := fileName + ":" + key
+					var tag string
+					if settingsExpectedToDiffer[settingKey] {
+						tag = " (expected to differ)"
+					} else if mattered[settingKey] {
+						if nodeType == types.UnspecifiedNodeType {
+							tag = " [MATTERS]"
+						} else {
+							tag = fmt.Sprintf(" [MATTERS for %s]", nodeType)
+						}
+					}
+
+					fmt.Printf("- %s: A=%s B=%s%s\n", key, formatValue(valueA, foundA), formatValue(valueB, foundB), tag)
+				}
+				if countDiff == 0 {
+					fmt.Println("- identical")
+				}
+			}
+
+			fmt.Println("\ngenesis.json:")
+			genesisHashA, err := sha256File(path.Join(homeA, "config", "genesis.json"))
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to hash genesis.json of A: %v\n", err)
+				return
+			}
+			genesisHashB, err := sha256File(path.Join(homeB, "config", "genesis.json"))
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to hash genesis.json of B: %v\n", err)
+				return
+			}
+			if genesisHashA == genesisHashB {
+				fmt.Printf("- hashes match: %s\n", genesisHashA)
+			} else {
+				countProblems++
+				fmt.Printf("- PROBLEM: hashes do not match\n  A: %s\n  B: %s\n", genesisHashA, genesisHashB)
+			}
+
+			compareKey := func(fileName string, expectSame bool, flagName string, consequence string) {
+				fmt.Printf("\n%s:\n", fileName)
+				keyA, err := readPrivKeyValue(path.Join(homeA, "config", fileName))
+				if err != nil {
+					exitWithErrorMsgf("ERR: failed to read %s of A: %v\n", fileName, err)
+					return
+				}
+				keyB, err := readPrivKeyValue(path.Join(homeB, "config", fileName))
+				if err != nil {
+					exitWithErrorMsgf("ERR: failed to read %s of B: %v\n", fileName, err)
+					return
+				}
+
+				same := keyA == keyB
+				switch {
+				case same && expectSame:
+					fmt.Println("- keys are the same, as expected")
+				case !same && !expectSame:
+					fmt.Println("- keys are different, as expected")
+				case same:
+					countProblems++
+					fmt.Printf("- PROBLEM: keys are the same, %s\n  > provide --%s if this is intended\n", consequence, flagName)
+				default:
+					countProblems++
+					fmt.Printf("- PROBLEM: keys are different but expected to be the same (--%s)\n", flagName)
+				}
+			}
+
+			compareKey(
+				"priv_validator_key.json", expectSameValidatorKey, flagSameValidatorKey,
+				"running both nodes at the same time will double sign",
+			)
+			compareKey(
+				"node_key.json", expectSameNodeKey, flagSameNodeKey,
+				"both nodes have the same node ID",
+			)
+
+			if countProblems > 0 {
+				exitWithErrorMsgf("ERR: found %d problem(s) between the two homes\n", countProblems)
+				return
+			}
+		},
+	}
+
+	cmd.Flags().String(flagType, "", fmt.Sprintf("type of node, to highlight differences which matter, can be: %s", validTargetValues))
+	cmd.Flags().Bool(flagSameValidatorKey, false, "priv_validator_key.json is intended to be the same, e.g. migrating validator")
+	cmd.Flags().Bool(flagSameNodeKey, false, "node_key.json is intended to be the same, e.g. migrating node to new hardware")
+
+	return cmd
+}
+
+// settingsMatterForNodeType returns the settings which are inspected by the check,
+// plus the settings matter for the node type if specified.
+func settingsMatterForNodeType(nodeType types.NodeType) map[string]bool {
+	mattered := make(map[string]bool)
+
+	addTyped := func(fileName string, typed any) {
+		// marshal zero-value struct to get all keys inspected by the check
+		bz, err := toml.Marshal(typed)
+		if err != nil {
+			panic(err)
+		}
+		var raw map[string]any
+		if err := toml.Unmarshal(bz, &raw); err != nil {
+			panic(err)
+		}
+		flattenSettings(raw, "", func(key, _ string) {
+			mattered[fileName+":"+key] = true
+		})
+	}
+
+	addTyped("app.toml", types.AppToml{
+		Api:       &types.ApiAppToml{},
+		JsonRpc:   &types.JsonRpcAppToml{},
+		StateSync: &types.StateSyncAppToml{},
+		Grpc:      &types.GrpcAppToml{},
+	})
+	addTyped("config.toml", types.ConfigToml{
+		P2P:       &types.P2pConfigToml{},
+		StateSync: &types.StateSyncConfigToml{},
+		Consensus: &types.ConsensusConfigToml{},
+		TxIndex:   &types.TxIndexConfigToml{},
+	})
+
+	for _, settingKey := range extraSettingsMatterPerNodeType[nodeType] {
+		mattered[settingKey] = true
+	}
+
+	return mattered
+}
+
+// readTomlSettings reads and flattens all settings of the TOML file.
+func readTomlSettings(filePath string) (map[string]string, error) {
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := toml.Unmarshal(bz, &raw); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", filePath)
+	}
+
+	settings := make(map[string]string)
+	flattenSettings(raw, "", func(key, value string) {
+		settings[key] = value
+	})
+	return settings, nil
+}
+
+// readPrivKeyValue reads the private key value from node_key.json or priv_validator_key.json.
+func readPrivKeyValue(filePath string) (string, error) {
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	var key struct {
+		PrivKey *struct {
+			Value string `json:"value"`
+		} `json:"priv_key"`
+	}
+	if err := json.Unmarshal(bz, &key); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal %s", filePath)
+	}
+	if key.PrivKey == nil || key.PrivKey.Value == "" {
+		return "", fmt.Errorf("priv_key is missing in %s", filePath)
+	}
+
+	return key.PrivKey.Value, nil
+}
+
+func unionKeys(a, b map[string]string) map[string]string {
+	union := make(map[string]string, len(a)+len(b))
+	for key, value := range a {
+		union[key] = value
+	}
+	for key, value := range b {
+		union[key] = value
+	}
+	return union
+}
+
+func init() {
+	rootCmd.AddCommand(GetDiffCmd())
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	gib = 1024 * 1024 * 1024
+
+	// minimumFreeDiskPercent is the free disk percent that any node type must keep
+	minimumFreeDiskPercent = 10
+	// minimumFreeDiskPercentArchival is the free disk percent that archival node should keep, as it grows fastest
+	minimumFreeDiskPercentArchival = 30
+	// recommendFreeDiskPercent is the free disk percent that non-archival node should keep
+	recommendFreeDiskPercent = 20
+	maximumUsedInodesPercent = 90
+
+	maxValidatorTxIndexSize    = 1 * gib
+	maxValidatorSnapshotsSize  = 1 * gib
+	maxValidatorEvmIndexerSize = 1 * gib
+)
+
+// diskReportEntries are the stores reported, relative to the home
+var diskReportEntries = []string{
+	"data/application.db",
+	"data/blockstore.db",
+	"data/state.db",
+	"data/tx_index.db",
+	"data/evidence.db",
+	"data/evmindexer.db",
+	"data/snapshots",
+	"data/wasm",
+	"wasm",
+}
+
+func GetDiskCmd() *cobra.Command {
+	validTargetValues := strings.Join(types.AllNodeTypeNames(), "/")
+
+	var cmd = &cobra.Command{
+		Use:   "disk [home]",
+		Short: "Report disk usage and free space of the data directory",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+
+			nodeType := types.UnspecifiedNodeType
+			typeName, _ := cmd.Flags().GetString(flagType)
+			if typeName != "" {
+				nodeType = types.NodeTypeFromString(typeName)
+				if nodeType == types.UnspecifiedNodeType {
+					exitWithErrorMsgf("ERR: Invalid node type, can be either %s\n", validTargetValues)
+					return
+				}
+			}
+
+			dataPath := path.Join(home, "data")
+			_, exists, isDir, err := utils.FileInfo(dataPath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to check data directory at %s: %v\n", dataPath, err)
+				return
+			}
+			if !exists || !isDir {
+				exitWithErrorMsgf("ERR: data directory does not exist: %s\n", dataPath)
+				return
+			}
+
+			sizes := make(map[string]int64)
+			fmt.Println("Size of stores:")
+			for _, entry := range diskReportEntries {
+				entryPath := path.Join(home, entry)
+				_, exists, _, err := utils.FileInfo(entryPath)
+				if err != nil {
+					exitWithErrorMsgf("ERR: failed to check %s: %v\n", entryPath, err)
+					return
+				}
+				if !exists {
+					continue
+				}
+
+				size, err := utils.DirSize(entryPath)
+				if err != nil {
+					exitWithErrorMsgf("ERR: failed to calculate size of %s: %v\n", entryPath, err)
+					return
+				}
+				sizes[entry] = size
+				fmt.Printf("- %-20s %10s\n", entry, formatBytes(size))
+			}
+
+			totalDataSize, err := utils.DirSize(dataPath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to calculate size of %s: %v\n", dataPath, err)
+				return
+			}
+			fmt.Printf("- %-20s %10s\n", "data (total)", formatBytes(totalDataSize))
+
+			usage, err := utils.GetDiskUsage(dataPath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to get disk usage of %s: %v\n", dataPath, err)
+				return
+			}
+
+			fmt.Println("Filesystem backing the data directory:")
+			fmt.Printf("- Free:   %s of %s (%.1f%%)\n", formatBytes(int64(usage.FreeBytes)), formatBytes(int64(usage.TotalBytes)), usage.FreePercent())
+			if usage.TotalInodes > 0 {
+				fmt.Printf("- Inodes: %d used of %d (%.1f%%)\n", usage.TotalInodes-usage.FreeInodes, usage.TotalInodes, usage.UsedInodesPercent())
+			}
+
+			procRoot, _ := cmd.Flags().GetString(flagProcRoot)
+			mounts, err := utils.ReadMounts(procRoot)
+			if err != nil {
+				fmt.Printf("Unable to report mounts backing each part of the home: %v\n", err)
+			} else {
+				fmt.Println("Mounts backing each part of the home:")
+				for _, entry := range append([]string{".", "config", "keyring-file", "keyring-test", "data"}, diskReportEntries...) {
+					entryPath := path.Join(home, entry)
+					resolved, err := filepath.EvalSymlinks(entryPath)
+					if err != nil {
+						continue
+					}
+					absEntryPath, _ := filepath.Abs(entryPath)
+					if resolved, err = filepath.Abs(resolved); err != nil {
+						continue
+					}
+
+					var link string
+					if resolved != absEntryPath {
+						link = " -> " + resolved
+					}
+					mount := utils.FindMount(mounts, resolved)
+					if mount == nil {
+						fmt.Printf("- %-20s%s [unknown mount]\n", entry, link)
+						continue
+					}
+					fmt.Printf("- %-20s%s [%s %s on %s]\n", entry, link, mount.MountPoint, mount.FsType, mount.Device)
+				}
+			}
+
+			checkDiskUsage(dataPath, nodeType, usage, sizes)
+
+			if len(checkRecords) > 0 {
+				printCheckRecords()
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String(flagType, "", fmt.Sprintf("type of node, to check against recommendation for the node type, can be: %s", validTargetValues))
+	cmd.Flags().String(flagProcRoot, utils.DefaultProcRoot, "where procfs is mounted, to read mounts")
+
+	return cmd
+}
+
+func checkDiskUsage(dataPath string, nodeType types.NodeType, usage utils.DiskUsage, sizes map[string]int64) {
+	diskTarget := fileTarget("disk", dataPath, "free-space")
+	evaluatedRule(diskTarget)
+	freePercent := usage.FreePercent()
+
+	if freePercent < minimumFreeDiskPercent {
+		fatalRecord(diskTarget, fmt.Sprintf("only %.1f%% disk space is free", freePercent), "expand the disk or prune the data")
+	} else if nodeType == types.ArchivalNode && freePercent < minimumFreeDiskPercentArchival {
+		warnRecord(
+			diskTarget,
+			fmt.Sprintf("only %.1f%% disk space is free, archival node should keep at least %d%% free", freePercent, minimumFreeDiskPercentArchival),
+			"expand the disk",
+		)
+	} else if nodeType != types.UnspecifiedNodeType && nodeType != types.ArchivalNode && freePercent < recommendFreeDiskPercent {
+		warnRecord(
+			diskTarget,
+			fmt.Sprintf("only %.1f%% disk space is free, should keep at least %d%% free", freePercent, recommendFreeDiskPercent),
+			"expand the disk or prune the data",
+		)
+	}
+
+	evaluatedRule(fileTarget("disk", dataPath, "inodes"))
+	if usage.UsedInodesPercent() > maximumUsedInodesPercent {
+		warnRecord(
+			fileTarget("disk", dataPath, "inodes"),
+			fmt.Sprintf("%.1f%% inodes are used", usage.UsedInodesPercent()),
+			"clean up small files or re-create the filesystem with more inodes",
+		)
+	}
+
+	if nodeType == types.ValidatorNode {
+		evaluatedRule(
+			fileTarget("disk", path.Join(dataPath, "tx_index.db"), "tx-index-size"),
+			fileTarget("disk", path.Join(dataPath, "snapshots"), "snapshots-size"),
+			fileTarget("disk", path.Join(dataPath, "evmindexer.db"), "evm-indexer-size"),
+		)
+		if size := sizes["data/tx_index.db"]; size > maxValidatorTxIndexSize {
+			warnRecord(
+				fileTarget("disk", path.Join(dataPath, "tx_index.db"), "tx-index-size"),
+				fmt.Sprintf("tx_index.db is %s on validator node, validator does not need tx index", formatBytes(size)),
+				"set indexer to \"null\" in [tx_index] section of config.toml, then remove tx_index.db while node stopped",
+			)
+		}
+		if size := sizes["data/snapshots"]; size > maxValidatorSnapshotsSize {
+			warnRecord(
+				fileTarget("disk", path.Join(dataPath, "snapshots"), "snapshots-size"),
+				fmt.Sprintf("snapshots is %s on validator node", formatBytes(size)),
+				"set snapshot-interval to 0 in app.toml, then remove snapshots while node stopped",
+			)
+		}
+		if size := sizes["data/evmindexer.db"]; size > maxValidatorEvmIndexerSize {
+			warnRecord(
+				fileTarget("disk", path.Join(dataPath, "evmindexer.db"), "evm-indexer-size"),
+				fmt.Sprintf("evmindexer.db is %s on validator node", formatBytes(size)),
+				"set enable-indexer to false in [json-rpc] section of app.toml, then remove evmindexer.db while node stopped",
+			)
+		}
+	}
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(GetDiskCmd())
+}
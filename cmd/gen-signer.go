@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	flagNodeAddr        = "node-addr"
+	flagTmkmsHome       = "tmkms-home"
+	flagBech32Prefix    = "bech32-prefix"
+	flagProtocolVersion = "protocol-version"
+	flagCosigners       = "cosigners"
+	flagThreshold       = "threshold"
+)
+
+func GetGenTmkmsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "gen-tmkms [home]",
+		Short: "Generate tmkms.toml for the validator to use tmkms as remote signer",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+
+			tmkmsHome, _ := cmd.Flags().GetString(flagTmkmsHome)
+			bech32Prefix, _ := cmd.Flags().GetString(flagBech32Prefix)
+			protocolVersion, _ := cmd.Flags().GetString(flagProtocolVersion)
+			nodeAddrFlag, _ := cmd.Flags().GetString(flagNodeAddr)
+
+			bech32Prefix = strings.TrimSpace(bech32Prefix)
+			if bech32Prefix == "" {
+				exitWithErrorMsgf("ERR: --%s is required, e.g. cosmos\n", flagBech32Prefix)
+				return
+			}
+
+			chainId, nodeAddr := readSignerNodeInfo(home, nodeAddrFlag)
+
+			const fileTmkmsToml = "tmkms.toml"
+			checkGeneratedFileNotExists(fileTmkmsToml)
+
+			tmkms := types.TmkmsToml{
+				Chain: []types.TmkmsChain{{
+					Id: chainId,
+					KeyFormat: &types.TmkmsKeyFormat{
+						Type:               "bech32",
+						AccountKeyPrefix:   bech32Prefix + "pub",
+						ConsensusKeyPrefix: bech32Prefix + "valconspub",
+					},
+					StateFile: path.Join(tmkmsHome, "state", chainId+"-consensus.json"),
+				}},
+				Providers: map[string][]types.TmkmsProvider{
+					"softsign": {{
+						ChainIds: []string{chainId},
+						KeyType:  "consensus",
+						Path:     path.Join(tmkmsHome, "secrets", chainId+"-consensus.key"),
+					}},
+				},
+				Validator: []types.TmkmsValidator{{
+					ChainId:         chainId,
+					Addr:            nodeAddr.String(),
+					SecretKey:       path.Join(tmkmsHome, "secrets", "kms-identity.key"),
+					ProtocolVersion: protocolVersion,
+					Reconnect:       true,
+				}},
+			}
+
+			bz, err := toml.Marshal(tmkms)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to marshal tmkms config: %v\n", err)
+				return
+			}
+			if err := os.WriteFile(fileTmkmsToml, bz, 0o600); err != nil {
+				exitWithErrorMsgf("ERR: failed to write %s: %v\n", fileTmkmsToml, err)
+				return
+			}
+
+			fmt.Println("Generated", fileTmkmsToml, "for chain", chainId)
+			fmt.Println("\nOn the signer machine:")
+			fmt.Printf("- copy %s to %s\n", fileTmkmsToml, path.Join(tmkmsHome, fileTmkmsToml))
+			fmt.Printf("- tmkms softsign import %s %s\n", path.Join(home, "config", "priv_validator_key.json"), path.Join(tmkmsHome, "secrets", chainId+"-consensus.key"))
+			fmt.Printf("- tmkms softsign keygen %s\n", path.Join(tmkmsHome, "secrets", "kms-identity.key"))
+			fmt.Println("- copy priv_validator_state.json of the node into the state_file, to keep double sign protection")
+			printSignerNodeChanges(home, nodeAddr)
+		},
+	}
+
+	cmd.Flags().String(flagNodeAddr, "", "private address for the node to listen for the signer, e.g. tcp://10.0.0.2:26659, default to priv_validator_laddr of config.toml")
+	cmd.Flags().String(flagTmkmsHome, "/root/.tmkms", "home of tmkms on the signer machine")
+	cmd.Flags().String(flagBech32Prefix, "", "bech32 prefix of the chain, e.g. cosmos")
+	cmd.Flags().String(flagProtocolVersion, "v0.34", "protocol version matches the CometBFT version of the node, v0.34 is used by v0.37 and v0.38 as well")
+
+	return cmd
+}
+
+func GetGenHorcruxCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "gen-horcrux [home]",
+		Short: "Generate horcrux config.yaml for the validator to use horcrux threshold signer",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+
+			cosigners, _ := cmd.Flags().GetStringSlice(flagCosigners)
+			threshold, _ := cmd.Flags().GetInt(flagThreshold)
+			nodeAddrFlag, _ := cmd.Flags().GetString(flagNodeAddr)
+
+			if len(cosigners) < 2 {
+				exitWithErrorMsgf("ERR: --%s requires at least 2 cosigners, e.g. tcp://10.0.0.11:2222,tcp://10.0.0.12:2222,tcp://10.0.0.13:2222\n", flagCosigners)
+				return
+			}
+			if threshold == 0 {
+				threshold = len(cosigners)/2 + 1
+			}
+			if threshold <= len(cosigners)/2 || threshold > len(cosigners) {
+				exitWithErrorMsgf("ERR: --%s must be greater than half and at most the number of cosigners\n", flagThreshold)
+				return
+			}
+
+			thresholdMode := &types.HorcruxThresholdMode{
+				Threshold:   threshold,
+				GrpcTimeout: "1000ms",
+				RaftTimeout: "1000ms",
+			}
+			uniqueTracker := make(map[string]bool)
+			for i, cosigner := range cosigners {
+				addr, err := parseSignerAddress(cosigner)
+				if err != nil || addr.scheme != "tcp" {
+					exitWithErrorMsgf("ERR: invalid cosigner address %s, must be tcp://<host>:<port>\n", cosigner)
+					return
+				}
+				if uniqueTracker[addr.String()] {
+					exitWithErrorMsgf("ERR: duplicate cosigner: %s\n", cosigner)
+					return
+				}
+				uniqueTracker[addr.String()] = true
+				thresholdMode.Cosigners = append(thresholdMode.Cosigners, types.HorcruxCosigner{
+					ShardId: i + 1,
+					P2pAddr: addr.String(),
+				})
+			}
+
+			chainId, nodeAddr := readSignerNodeInfo(home, nodeAddrFlag)
+
+			const fileHorcruxConfig = "config.yaml"
+			checkGeneratedFileNotExists(fileHorcruxConfig)
+
+			bz, err := yaml.Marshal(types.HorcruxConfig{
+				SignMode:      "threshold",
+				ThresholdMode: thresholdMode,
+				ChainNodes:    []types.HorcruxChainNode{{PrivValAddr: nodeAddr.String()}},
+			})
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to marshal horcrux config: %v\n", err)
+				return
+			}
+			if err := os.WriteFile(fileHorcruxConfig, bz, 0o600); err != nil {
+				exitWithErrorMsgf("ERR: failed to write %s: %v\n", fileHorcruxConfig, err)
+				return
+			}
+
+			fmt.Println("Generated", fileHorcruxConfig, "for chain", chainId)
+			fmt.Println("\nOn a secure machine which has the key:")
+			fmt.Printf("- horcrux create-ed25519-shards --chain-id %s --key-file %s --threshold %d --shards %d\n", chainId, path.Join(home, "config", "priv_validator_key.json"), threshold, len(cosigners))
+			fmt.Println("- horcrux create-ecies-shards --shards", len(cosigners))
+			fmt.Println("On each cosigner:")
+			fmt.Printf("- copy %s, the ed25519 shard and the ecies shard of the cosigner into ~/.horcrux\n", fileHorcruxConfig)
+			fmt.Printf("- copy priv_validator_state.json of the node into ~/.horcrux/state/%s_priv_validator_state.json, to keep double sign protection\n", chainId)
+			printSignerNodeChanges(home, nodeAddr)
+		},
+	}
+
+	cmd.Flags().String(flagNodeAddr, "", "private address for the node to listen for the signer, e.g. tcp://10.0.0.2:26659, default to priv_validator_laddr of config.toml")
+	cmd.Flags().StringSlice(flagCosigners, nil, "p2p addresses of cosigners, shard IDs are assigned by order, e.g. tcp://10.0.0.11:2222,tcp://10.0.0.12:2222,tcp://10.0.0.13:2222")
+	cmd.Flags().Int(flagThreshold, 0, "number of cosigners required to sign, default to majority")
+
+	return cmd
+}
+
+// readSignerNodeInfo reads chain id from genesis, cross-checked with client.toml,
+// and the private address that the node listens for the signer.
+func readSignerNodeInfo(home string, nodeAddrFlag string) (chainId string, nodeAddr *signerAddress) {
+	configPath := path.Join(home, "config")
+
+	chainId, err := utils.ReadGenesisChainId(path.Join(configPath, "genesis.json"))
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read chain id from genesis: %v\n", err)
+		return
+	}
+	clientToml, err := readTomlSettings(path.Join(configPath, "client.toml"))
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to read client.toml: %v\n", err)
+		return
+	}
+	if clientChainId := clientToml["chain-id"]; clientChainId != "" && clientChainId != chainId {
+		exitWithErrorMsgf("ERR: chain-id %s in client.toml does not match chain_id %s of genesis\n", clientChainId, chainId)
+		return
+	}
+
+	laddr := nodeAddrFlag
+	if laddr == "" {
+		configToml, err := readTomlSettings(path.Join(configPath, "config.toml"))
+		if err != nil {
+			exitWithErrorMsgf("ERR: failed to read config.toml: %v\n", err)
+			return
+		}
+		laddr = configToml["priv_validator_laddr"]
+	}
+	if laddr == "" {
+		exitWithErrorMsgf("ERR: priv_validator_laddr is not set in config.toml, provide --%s\n", flagNodeAddr)
+		return
+	}
+
+	nodeAddr, err = parseSignerAddress(laddr)
+	if err != nil || nodeAddr.scheme != "tcp" {
+		exitWithErrorMsgf("ERR: invalid node address %s, must be tcp://<private IP>:<port>\n", laddr)
+		return
+	}
+	if private, known := isPrivateHost(nodeAddr.host); !known || !private || nodeAddr.host == "localhost" || strings.HasPrefix(nodeAddr.host, "127.") {
+		exitWithErrorMsgf("ERR: node address %s must be a private IP reachable by the signer, provide --%s\n", laddr, flagNodeAddr)
+		return
+	}
+
+	return chainId, nodeAddr
+}
+
+func printSignerNodeChanges(home string, nodeAddr *signerAddress) {
+	configTomlFilePath := path.Join(home, "config", "config.toml")
+	fmt.Println("\nOn the node:")
+	fmt.Printf("- set in %s:\n  priv_validator_laddr = \"%s\"\n", configTomlFilePath, nodeAddr.String())
+	fmt.Printf("- allow port %s on firewall only from the signer\n", nodeAddr.port)
+	fmt.Printf("- after the signer is ready, replace priv_key value in priv_validator_key.json with placeholder %s\n", placeholderPrivValidatorKeyValue)
+	fmt.Printf("- verify by: nodesc check %s --type validator --remote-signer --signer-config <generated file>\n", home)
+}
+
+func checkGeneratedFileNotExists(file string) {
+	_, exists, _, err := utils.FileInfo(file)
+	if err != nil {
+		exitWithErrorMsgf("ERR: failed to check if %s exists: %v\n", file, err)
+		return
+	}
+	if exists {
+		exitWithErrorMsgf("ERR: %s already exist\n", file)
+		return
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(GetGenTmkmsCmd())
+	rootCmd.AddCommand(GetGenHorcruxCmd())
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+)
+
+const (
+	flagMigrateFrom       = "from"
+	flagMigrateTo         = "to"
+	flagMigrateOldService = "old-service"
+	flagMigrateNewService = "new-service"
+)
+
+func GetMigrateCheckCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "migrate-check",
+		Short: "Verify each step of migrating a validator to a new home/machine, to prevent double sign",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			oldHome, _ := cmd.Flags().GetString(flagMigrateFrom)
+			newHome, _ := cmd.Flags().GetString(flagMigrateTo)
+			oldService, _ := cmd.Flags().GetString(flagMigrateOldService)
+			newService, _ := cmd.Flags().GetString(flagMigrateNewService)
+
+			// the new service is required, starting it on boot next to the old one is a double sign
+			if oldHome == "" || newHome == "" || oldService == "" || newService == "" {
+				exitWithErrorMsgf("ERR: --%s, --%s, --%s and --%s are required\n", flagMigrateFrom, flagMigrateTo, flagMigrateOldService, flagMigrateNewService)
+				return
+			}
+
+			oldService = normalizeSystemdUnitName(oldService)
+			newService = normalizeSystemdUnitName(newService)
+
+			var stepNo int
+			var blocked bool
+			// step prints a step of the checklist, once a step failed, all the following steps are blocked
+			step := func(title string, check func() (ok bool, detail string), instruction string) {
+				stepNo++
+				if blocked {
+					fmt.Printf("%d. [BLOCKED] %s\n", stepNo, title)
+					return
+				}
+
+				ok, detail := check()
+				if ok {
+					fmt.Printf("%d. [OK] %s\n", stepNo, title)
+				} else {
+					blocked = true
+					fmt.Printf("%d. [FAIL] %s\n", stepNo, title)
+				}
+				if detail != "" {
+					fmt.Printf("   %s\n", detail)
+				}
+				if !ok && instruction != "" {
+					fmt.Printf("   > %s\n", instruction)
+				}
+			}
+
+			fmt.Printf("Validator migration checklist\n from: %s (service %s)\n to:   %s (service %s)\n\n", oldHome, oldService, newHome, newService)
+
+			step(fmt.Sprintf("Old service %s is stopped", oldService), func() (bool, string) {
+				state, err := getSystemdUnitState(oldService)
+				if err != nil {
+					return false, fmt.Sprintf("failed to query state of %s: %v, verify manually", oldService, err)
+				}
+				if state.isNotFound() {
+					// a typo would pass as inactive
+					return false, fmt.Sprintf("unit %s is not found, verify the unit name, or that the old validator is stopped manually", oldService)
+				}
+				if state.isRunning() {
+					return false, fmt.Sprintf("service state is %s", state.ActiveState)
+				}
+				return true, fmt.Sprintf("service state is %s", state.ActiveState)
+			}, "sudo systemctl stop "+oldService)
+
+			step(fmt.Sprintf("Old service %s is disabled on boot", oldService), func() (bool, string) {
+				state, err := getSystemdUnitState(oldService)
+				if err != nil {
+					return false, fmt.Sprintf("failed to query state of %s: %v", oldService, err)
+				}
+				return !state.isEnabledOnBoot(), fmt.Sprintf("unit file state is %s", state.UnitFileState)
+			}, "sudo systemctl disable "+oldService)
+
+			step("Consensus keys of both homes match", func() (bool, string) {
+				oldKey, err := readPrivKeyValue(path.Join(oldHome, "config", "priv_validator_key.json"))
+				if err != nil {
+					return false, err.Error()
+				}
+				newKey, err := readPrivKeyValue(path.Join(newHome, "config", "priv_validator_key.json"))
+				if err != nil {
+					return false, err.Error()
+				}
+				if oldKey != newKey {
+					return false, "priv_validator_key.json of the new home is different from the old one"
+				}
+				return true, ""
+			}, fmt.Sprintf("copy %s to %s", path.Join(oldHome, "config", "priv_validator_key.json"), path.Join(newHome, "config", "priv_validator_key.json")))
+
+			step("Signing state of the new home is at or above the old one", func() (bool, string) {
+				oldState, err := readPrivValidatorState(path.Join(oldHome, "data", "priv_validator_state.json"))
+				if err != nil {
+					return false, err.Error()
+				}
+				newState, err := readPrivValidatorState(path.Join(newHome, "data", "priv_validator_state.json"))
+				if err != nil {
+					return false, err.Error()
+				}
+				cmp, err := newState.Compare(oldState)
+				if err != nil {
+					return false, err.Error()
+				}
+				detail := fmt.Sprintf("old: %s, new: %s", oldState, newState)
+				return cmp >= 0, detail
+			}, fmt.Sprintf("after the old node stopped, copy %s to %s", path.Join(oldHome, "data", "priv_validator_state.json"), path.Join(newHome, "data", "priv_validator_state.json")))
+
+			step(fmt.Sprintf("New service %s is not enabled on boot", newService), func() (bool, string) {
+				state, err := getSystemdUnitState(newService)
+				if err != nil {
+					return false, fmt.Sprintf("failed to query state of %s: %v", newService, err)
+				}
+				if state.isNotFound() {
+					return false, fmt.Sprintf("unit %s is not found, install the service file first", newService)
+				}
+				return !state.isEnabledOnBoot(), fmt.Sprintf("unit file state is %s", state.UnitFileState)
+			}, "sudo systemctl disable "+newService)
+
+			stepNo++
+			if blocked {
+				fmt.Printf("%d. [BLOCKED] Start the new validator\n", stepNo)
+				exitWithErrorMsg("ERR: migration is not safe yet, resolve the failed step and re-run this check")
+				return
+			}
+
+			fmt.Printf("%d. [READY] Start the new validator\n", stepNo)
+			fmt.Printf("   > sudo systemctl start %s\n", newService)
+			fmt.Println("WARN: never start the old validator again, keep it disabled or remove its priv_validator_key.json")
+		},
+	}
+
+	cmd.Flags().String(flagMigrateFrom, "", "home of the old validator")
+	cmd.Flags().String(flagMigrateTo, "", "home of the new validator")
+	cmd.Flags().String(flagMigrateOldService, "", "systemd unit of the old validator")
+	cmd.Flags().String(flagMigrateNewService, "", "systemd unit of the new validator, required")
+
+	return cmd
+}
+
+func readPrivValidatorState(filePath string) (types.PrivValidatorState, error) {
+	var pvs types.PrivValidatorState
+
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return pvs, err
+	}
+
+	err = json.Unmarshal(bz, &pvs)
+	if err != nil {
+		return pvs, fmt.Errorf("failed to unmarshal %s: %v", filePath, err)
+	}
+
+	return pvs, nil
+}
+
+func init() {
+	rootCmd.AddCommand(GetMigrateCheckCmd())
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	flagProbeTimeout     = "timeout"
+	flagProbeConcurrency = "concurrency"
+)
+
+// peerProbeStatus is the result of probing a peer.
+type peerProbeStatus string
+
+const (
+	peerReachable  peerProbeStatus = "reachable"
+	peerMismatched peerProbeStatus = "mismatched"
+	peerDead       peerProbeStatus = "dead"
+)
+
+type peerProbeResult struct {
+	source   string // seeds or persistent_peers
+	entry    string
+	status   peerProbeStatus
+	remoteId string
+	err      error
+	elapsed  time.Duration
+}
+
+func GetPeersCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "peers",
+		Short: "Inspect peers of a node home",
+	}
+
+	cmd.AddCommand(getPeersProbeCmd(), getPeersAddrBookCmd())
+
+	return cmd
+}
+
+func getPeersProbeCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "probe [home]",
+		Short: "Dial seeds and persistent_peers, verify node ID of each peer via SecretConnection handshake",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			home := args[0]
+			timeout, _ := cmd.Flags().GetDuration(flagProbeTimeout)
+			concurrency, _ := cmd.Flags().GetInt(flagProbeConcurrency)
+			if timeout <= 0 {
+				exitWithErrorMsgf("ERR: --%s must be positive\n", flagProbeTimeout)
+				return
+			}
+			if concurrency < 1 {
+				exitWithErrorMsgf("ERR: --%s must be at least 1\n", flagProbeConcurrency)
+				return
+			}
+
+			configTomlFilePath := path.Join(home, "config", "config.toml")
+			bz, err := os.ReadFile(configTomlFilePath)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to read config.toml file at %s: %v\n", configTomlFilePath, err)
+				return
+			}
+			var configToml types.ConfigToml
+			if err := toml.Unmarshal(bz, &configToml); err != nil {
+				exitWithErrorMsgf("ERR: failed to unmarshal config.toml file at %s: %v\n", configTomlFilePath, err)
+				return
+			}
+			if configToml.P2P == nil {
+				exitWithErrorMsgf("ERR: [p2p] section is missing in config.toml file at %s\n", configTomlFilePath)
+				return
+			}
+
+			var results []*peerProbeResult
+			var peers []*utils.Peer
+			for _, source := range []struct {
+				key  string
+				list string
+			}{
+				{key: "seeds", list: configToml.P2P.Seeds},
+				{key: "persistent_peers", list: configToml.P2P.PersistentPeers},
+			} {
+				for _, entry := range utils.SplitPeerList(source.list) {
+					peer, err := utils.ParsePeer(entry)
+					if err != nil {
+						fmt.Printf("SKIP: invalid entry %q in %s: %v\n", entry, source.key, err)
+						continue
+					}
+					results = append(results, &peerProbeResult{source: source.key, entry: entry})
+					peers = append(peers, peer)
+				}
+			}
+			if len(peers) == 0 {
+				exitWithErrorMsgf("ERR: no peer to probe in seeds and persistent_peers of %s\n", configTomlFilePath)
+				return
+			}
+
+			// a throwaway node key, the probe is not a node of the network
+			_, probeKey, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				exitWithErrorMsgf("ERR: failed to generate probe key: %v\n", err)
+				return
+			}
+
+			fmt.Printf("Probing %d peer(s), timeout %s, concurrency %d\n", len(peers), timeout, concurrency)
+			probePeers(peers, results, probeKey, timeout, concurrency)
+
+			var countProblems int
+			for _, result := range results {
+				switch result.status {
+				case peerReachable:
+					fmt.Printf("- %s: %s (%s) in %s\n", result.status, result.entry, result.source, result.elapsed.Round(time.Millisecond))
+				case peerMismatched:
+					countProblems++
+					fmt.Printf("- %s: %s (%s), remote node ID is %s\n", result.status, result.entry, result.source, result.remoteId)
+				default:
+					countProblems++
+					fmt.Printf("- %s: %s (%s), %v\n", result.status, result.entry, result.source, result.err)
+				}
+			}
+
+			if countProblems > 0 {
+				exitWithErrorMsgf("ERR: %d of %d peer(s) are not usable\n", countProblems, len(results))
+				return
+			}
+			fmt.Println("All peers are reachable")
+		},
+	}
+
+	cmd.Flags().Duration(flagProbeTimeout, 5*time.Second, "timeout of dialing and handshaking with each peer")
+	cmd.Flags().Int(flagProbeConcurrency, 8, "number of peers to probe at the same time")
+
+	return cmd
+}
+
+// probePeers dials the peers with bounded concurrency and fills the results at the same index.
+func probePeers(peers []*utils.Peer, results []*peerProbeResult, probeKey ed25519.PrivateKey, timeout time.Duration, concurrency int) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	for i, peer := range peers {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(peer *utils.Peer, result *peerProbeResult) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			startTime := time.Now()
+			remoteId, err := probePeer(peer, probeKey, timeout)
+			result.elapsed = time.Since(startTime)
+			switch {
+			case err != nil:
+				result.status = peerDead
+				result.err = err
+			case remoteId != peer.Id:
+				result.status = peerMismatched
+				result.remoteId = remoteId
+			default:
+				result.status = peerReachable
+			}
+		}(peer, results[i])
+	}
+	wg.Wait()
+}
+
+// probePeer dials the peer and returns the node ID proven by the SecretConnection handshake.
+func probePeer(peer *utils.Peer, probeKey ed25519.PrivateKey, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("tcp", peer.Address(), timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+	sc, err := utils.MakeSecretConnection(conn, probeKey)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(utils.ConsensusAddress(utils.PubKeyTypeEd25519, sc.RemotePubKey)), nil
+}
+
+func init() {
+	rootCmd.AddCommand(GetPeersCmd())
+}
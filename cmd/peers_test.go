@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/utils"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPeerStub listens on loopback and performs the SecretConnection handshake with the node key, returns id@host:port.
+func newPeerStub(t *testing.T) string {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+				_, _ = utils.MakeSecretConnection(conn, privKey)
+			}()
+		}
+	}()
+
+	nodeId := hex.EncodeToString(utils.ConsensusAddress(utils.PubKeyTypeEd25519, pubKey))
+	return fmt.Sprintf("%s@%s", nodeId, listener.Addr().String())
+}
+
+// closedPort returns address of a loopback port which nothing listens on.
+func closedPort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func TestProbePeers(t *testing.T) {
+	reachable := newPeerStub(t)
+	stubId, stubAddress, _ := strings.Cut(newPeerStub(t), "@")
+	wrongId := strings.Repeat("ab", 20)
+	mismatched := fmt.Sprintf("%s@%s", wrongId, stubAddress)
+	dead := fmt.Sprintf("%s@%s", wrongId, closedPort(t))
+
+	entries := []string{reachable, mismatched, dead}
+	peers := make([]*utils.Peer, len(entries))
+	results := make([]*peerProbeResult, len(entries))
+	for i, entry := range entries {
+		peer, err := utils.ParsePeer(entry)
+		if err != nil {
+			t.Fatalf("failed to parse peer %s: %v", entry, err)
+		}
+		peers[i] = peer
+		results[i] = &peerProbeResult{source: "persistent_peers", entry: entry}
+	}
+
+	_, probeKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probePeers(peers, results, probeKey, 5*time.Second, 2)
+
+	if results[0].status != peerReachable {
+		t.Errorf("%s: status = %s, want %s (err: %v)", reachable, results[0].status, peerReachable, results[0].err)
+	}
+
+	if results[1].status != peerMismatched {
+		t.Errorf("%s: status = %s, want %s (err: %v)", mismatched, results[1].status, peerMismatched, results[1].err)
+	} else if results[1].remoteId != stubId {
+		t.Errorf("%s: remote ID = %s, want %s", mismatched, results[1].remoteId, stubId)
+	}
+
+	if results[2].status != peerDead {
+		t.Errorf("%s: status = %s, want %s", dead, results[2].status, peerDead)
+	} else if results[2].err == nil {
+		t.Errorf("%s: dead peer should have the dial error", dead)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// normalizeSystemdUnitName appends ".service" if the unit name has no type suffix.
+func normalizeSystemdUnitName(unitName string) string {
+	unitName = filepath.Base(strings.TrimSpace(unitName))
+	if filepath.Ext(unitName) == "" {
+		unitName += ".service"
+	}
+	return unitName
+}
+
+// isSystemdUnitEnabled returns true if the unit is wanted by any target, e.g. systemd-timesyncd is wanted by sysinit.target.
+// Masked units are not enabled.
+func isSystemdUnitEnabled(systemDir string, unitName string) (bool, error) {
+	unitName = normalizeSystemdUnitName(unitName)
+
+	if target, err := os.Readlink(filepath.Join(systemDir, unitName)); err == nil && target == "/dev/null" {
+		return false, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(systemDir, "*.wants", unitName))
+	if err != nil {
+		return false, err
+	}
+	return len(matches) > 0, nil
+}
+
+// systemdUnitState is the state of a unit reported by systemd itself, covers units in /lib/systemd/system,
+// aliases, drop-ins and units enabled by any target.
+type systemdUnitState struct {
+	LoadState     string // e.g. loaded, not-found, masked
+	ActiveState   string // e.g. active, inactive, failed, activating
+	UnitFileState string // e.g. enabled, disabled, static, masked
+}
+
+// isNotFound returns true if systemd does not know the unit, e.g. typo in the unit name.
+func (s systemdUnitState) isNotFound() bool {
+	return s.LoadState == "not-found"
+}
+
+// isRunning returns true if the unit is running or about to run.
+func (s systemdUnitState) isRunning() bool {
+	switch s.ActiveState {
+	case "active", "activating", "reloading", "deactivating":
+		return true
+	default:
+		return false
+	}
+}
+
+// isEnabledOnBoot returns true if the unit is started on boot.
+func (s systemdUnitState) isEnabledOnBoot() bool {
+	return s.UnitFileState == "enabled" || s.UnitFileState == "enabled-runtime"
+}
+
+// getSystemdUnitState queries state of the unit by `systemctl show`.
+func getSystemdUnitState(unitName string) (systemdUnitState, error) {
+	output, err := exec.Command("systemctl", "show", "-p", "LoadState,ActiveState,UnitFileState", normalizeSystemdUnitName(unitName)).Output()
+	if err != nil {
+		return systemdUnitState{}, err
+	}
+	return parseSystemdUnitState(string(output))
+}
+
+// parseSystemdUnitState parses the key=value output of `systemctl show`.
+func parseSystemdUnitState(output string) (systemdUnitState, error) {
+	var state systemdUnitState
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "LoadState":
+			state.LoadState = value
+		case "ActiveState":
+			state.ActiveState = value
+		case "UnitFileState":
+			state.UnitFileState = value
+		}
+	}
+	if state.LoadState == "" || state.ActiveState == "" {
+		return state, fmt.Errorf("unexpected output of systemctl show: %q", strings.TrimSpace(output))
+	}
+	return state, nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import "testing"
+
+func TestParseSystemdUnitState(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantErr     bool
+		notFound    bool
+		running     bool
+		enabledBoot bool
+	}{
+		{
+			name:        "running and enabled",
+			output:      "LoadState=loaded\nActiveState=active\nUnitFileState=enabled\n",
+			running:     true,
+			enabledBoot: true,
+		},
+		{
+			name:   "stopped and disabled",
+			output: "LoadState=loaded\nActiveState=inactive\nUnitFileState=disabled\n",
+		},
+		{
+			name:   "masked",
+			output: "LoadState=masked\nActiveState=inactive\nUnitFileState=masked\n",
+		},
+		{
+			name:     "typo in unit name",
+			output:   "LoadState=not-found\nActiveState=inactive\nUnitFileState=\n",
+			notFound: true,
+		},
+		{
+			name:    "unexpected output",
+			output:  "Failed to connect to bus: No such file or directory\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := parseSystemdUnitState(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %t, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if state.isNotFound() != tt.notFound || state.isRunning() != tt.running || state.isEnabledOnBoot() != tt.enabledBoot {
+				t.Fatalf("unexpected state %+v", state)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"regexp"
 	"sort"
 	"strings"
 )
@@ -17,7 +16,20 @@ func printfStdErr(format string, a ...any) {
 	fmt.Fprintf(os.Stderr, format, a...)
 }
 
+// exitHooks are executed before exiting due to error, e.g. to persist the result of an incomplete check.
+// The hook receives the error message which causes the exit.
+var exitHooks []func(exitError string)
+
+func runExitHooks(exitError string) {
+	hooks := exitHooks
+	exitHooks = nil // prevent re-entrance if a hook exits with error
+	for _, hook := range hooks {
+		hook(strings.TrimSpace(exitError))
+	}
+}
+
 func exitWithErrorMsg(error string) {
+	runExitHooks(error)
 	printCheckRecords()
 	printlnStdErr()
 	printlnStdErr(error)
@@ -25,6 +37,7 @@ func exitWithErrorMsg(error string) {
 }
 
 func exitWithErrorMsgf(format string, a ...any) {
+	runExitHooks(fmt.Sprintf(format, a...))
 	printCheckRecords()
 	printlnStdErr()
 	printfStdErr(format, a...)
@@ -65,12 +78,6 @@ func printCheckRecords() {
 	}
 }
 
-var regexPeerPlus = regexp.MustCompile(`^[a-f\d]{40}@(([^:]+)|(\[[a-f\d]*(:+[a-f\d]+)+])):\d{1,5}(,[a-f\d]{40}@(([^:]+)|(\[[a-f\d]*(:+[a-f\d]+)+])):\d{1,5})*$`)
-
-func isValidPeer(peer string) bool {
-	return regexPeerPlus.MatchString(peer)
-}
-
 func isEmptyDir(path string) (bool, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,35 @@
+package types
+
+// AddrBook is the content of config/addrbook.json, written by PEX reactor of CometBFT.
+type AddrBook struct {
+	Key   string         `json:"key"`
+	Addrs []KnownAddress `json:"addrs"`
+}
+
+// KnownAddress is an entry of the address book.
+type KnownAddress struct {
+	Addr        AddrBookNetAddress `json:"addr"`
+	Src         AddrBookNetAddress `json:"src"`
+	Buckets     []int              `json:"buckets"`
+	Attempts    int32              `json:"attempts"`
+	BucketType  byte               `json:"bucket_type"`
+	LastAttempt string             `json:"last_attempt"`
+	LastSuccess string             `json:"last_success"`
+}
+
+type AddrBookNetAddress struct {
+	Id   string `json:"id"`
+	Ip   string `json:"ip"`
+	Port uint16 `json:"port"`
+}
+
+// Bucket types of the address book, new bucket holds addresses which never connected successfully.
+const (
+	AddrBookBucketTypeNew byte = 0x01
+	AddrBookBucketTypeOld byte = 0x02
+)
+
+// NeverSucceeded returns true if the node never connected to the address.
+func (a KnownAddress) NeverSucceeded() bool {
+	return a.LastSuccess == "" || a.LastSuccess == "0001-01-01T00:00:00Z"
+}
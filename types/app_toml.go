@@ -29,6 +29,7 @@ type AppToml struct {
 	HaltHeight        int64             `toml:"halt-height"`
 	HaltTime          int64             `toml:"halt-time"`
 	MinRetainsBlock   uint              `toml:"min-retain-blocks"`
+	AppDbBackend      string            `toml:"app-db-backend"`
 	Api               *ApiAppToml       `toml:"api"`
 	JsonRpc           *JsonRpcAppToml   `toml:"json-rpc"`
 	StateSync         *StateSyncAppToml `toml:"state-sync"`
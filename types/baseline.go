@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// Baseline is a snapshot of the known-good state of a node home, used to detect drift between runs.
+type Baseline struct {
+	Version   int                     `json:"version"`
+	Home      string                  `json:"home"`
+	CreatedAt time.Time               `json:"created_at"`
+	Files     map[string]BaselineFile `json:"files"`    // relative path to home => file
+	Settings  map[string]string       `json:"settings"` // "<file>:<dotted key>" => value
+	Service   *BaselineService        `json:"service,omitempty"`
+}
+
+type BaselineFile struct {
+	Perm   string `json:"perm"`
+	IsDir  bool   `json:"is_dir,omitempty"`
+	Sha256 string `json:"sha256,omitempty"` // empty for directories and files which change at runtime
+}
+
+type BaselineService struct {
+	Path     string            `json:"path"`
+	Perm     string            `json:"perm"`
+	Sha256   string            `json:"sha256"`
+	Enabled  bool              `json:"enabled"`
+	Settings map[string]string `json:"settings"` // "<section>.<key>" => value
+}
@@ -0,0 +1,59 @@
+package types
+
+import "encoding/json"
+
+// ChainRegistryChain is the part of chain.json of the cosmos chain-registry used by the checks.
+type ChainRegistryChain struct {
+	ChainName    string                 `json:"chain_name"`
+	ChainId      string                 `json:"chain_id"`
+	Bech32Prefix string                 `json:"bech32_prefix"`
+	Fees         *ChainRegistryFees     `json:"fees"`
+	Peers        *ChainRegistryPeers    `json:"peers"`
+	Codebase     *ChainRegistryCodebase `json:"codebase"`
+}
+
+type ChainRegistryFees struct {
+	FeeTokens []ChainRegistryFeeToken `json:"fee_tokens"`
+}
+
+// ChainRegistryFeeToken holds gas prices suggested to wallets, prices are kept as json.Number to not lose precision.
+type ChainRegistryFeeToken struct {
+	Denom            string      `json:"denom"`
+	FixedMinGasPrice json.Number `json:"fixed_min_gas_price"`
+	LowGasPrice      json.Number `json:"low_gas_price"`
+	AverageGasPrice  json.Number `json:"average_gas_price"`
+	HighGasPrice     json.Number `json:"high_gas_price"`
+}
+
+type ChainRegistryPeers struct {
+	Seeds           []ChainRegistryPeer `json:"seeds"`
+	PersistentPeers []ChainRegistryPeer `json:"persistent_peers"`
+}
+
+type ChainRegistryPeer struct {
+	Id       string `json:"id"`
+	Address  string `json:"address"`
+	Provider string `json:"provider"`
+}
+
+type ChainRegistryCodebase struct {
+	Genesis *ChainRegistryGenesis `json:"genesis"`
+}
+
+type ChainRegistryGenesis struct {
+	GenesisUrl string `json:"genesis_url"`
+	Sha256     string `json:"sha256"` // not in the registry schema, added locally to verify genesis.json
+}
+
+// FeeToken returns the fee token of the denom, nil if not found.
+func (c ChainRegistryChain) FeeToken(denom string) *ChainRegistryFeeToken {
+	if c.Fees == nil {
+		return nil
+	}
+	for i, feeToken := range c.Fees.FeeTokens {
+		if feeToken.Denom == denom {
+			return &c.Fees.FeeTokens[i]
+		}
+	}
+	return nil
+}
@@ -1,12 +1,15 @@
 package types
 
 type P2pConfigToml struct {
-	Seeds               string `toml:"seeds"`
-	Laddr               string `toml:"laddr"`
-	PersistentPeers     string `toml:"persistent_peers"`
-	MaxNumInboundPeers  int    `toml:"max_num_inbound_peers"`
-	MaxNumOutboundPeers int    `toml:"max_num_outbound_peers"`
-	SeedMode            bool   `toml:"seed_mode"`
+	Seeds                string `toml:"seeds"`
+	Laddr                string `toml:"laddr"`
+	PersistentPeers      string `toml:"persistent_peers"`
+	MaxNumInboundPeers   int    `toml:"max_num_inbound_peers"`
+	MaxNumOutboundPeers  int    `toml:"max_num_outbound_peers"`
+	SeedMode             bool   `toml:"seed_mode"`
+	Pex                  *bool  `toml:"pex"`
+	UnconditionalPeerIds string `toml:"unconditional_peer_ids"`
+	PrivatePeerIds       string `toml:"private_peer_ids"`
 }
 
 type StateSyncConfigToml struct {
@@ -23,9 +26,11 @@ type TxIndexConfigToml struct {
 }
 
 type ConfigToml struct {
-	Moniker   string               `toml:"moniker"`
-	P2P       *P2pConfigToml       `toml:"p2p"`
-	StateSync *StateSyncConfigToml `toml:"statesync"`
-	Consensus *ConsensusConfigToml `toml:"consensus"`
-	TxIndex   *TxIndexConfigToml   `toml:"tx_index"`
+	Moniker            string               `toml:"moniker"`
+	DbBackend          string               `toml:"db_backend"`
+	PrivValidatorLaddr string               `toml:"priv_validator_laddr"`
+	P2P                *P2pConfigToml       `toml:"p2p"`
+	StateSync          *StateSyncConfigToml `toml:"statesync"`
+	Consensus          *ConsensusConfigToml `toml:"consensus"`
+	TxIndex            *TxIndexConfigToml   `toml:"tx_index"`
 }
@@ -0,0 +1,200 @@
+package types
+
+// Genesis is the part of genesis.json used by the checks, other keys are skipped.
+type Genesis struct {
+	ChainId         string                  `json:"chain_id"`
+	ConsensusParams *GenesisConsensusParams `json:"consensus_params"` // CometBFT genesis
+	Consensus       *GenesisConsensus       `json:"consensus"`        // SDK v0.50+ genesis
+	Validators      []GenesisValidator      `json:"validators"`
+	AppState        *GenesisAppState        `json:"app_state"`
+}
+
+type GenesisConsensus struct {
+	Params     *GenesisConsensusParams `json:"params"`
+	Validators []GenesisValidator      `json:"validators"`
+}
+
+// GenesisValidator is an entry of the initial validator set of CometBFT.
+type GenesisValidator struct {
+	Address string `json:"address"`
+	PubKey  struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"pub_key"`
+	Power string `json:"power"`
+	Name  string `json:"name"`
+}
+
+// GenesisAnyPubKey is a public key packed in Any, as in gentx and staking genesis.
+type GenesisAnyPubKey struct {
+	Type string `json:"@type"`
+	Key  string `json:"key"`
+}
+
+type GenesisDescription struct {
+	Moniker string `json:"moniker"`
+}
+
+type GenesisConsensusParams struct {
+	Validator *GenesisValidatorParams `json:"validator"`
+}
+
+type GenesisValidatorParams struct {
+	PubKeyTypes []string `json:"pub_key_types"`
+}
+
+type GenesisAppState struct {
+	Auth      *GenesisAuthState      `json:"auth"`
+	Bank      *GenesisBankState      `json:"bank"`
+	Genutil   *GenesisGenutilState   `json:"genutil"`
+	Staking   *GenesisStakingState   `json:"staking"`
+	Evm       *GenesisEvmState       `json:"evm"`       // EVM chains
+	Feemarket *GenesisFeemarketState `json:"feemarket"` // EVM chains
+}
+
+type GenesisAuthState struct {
+	Accounts []GenesisAccount `json:"accounts"`
+}
+
+type GenesisAccount struct {
+	Address     string `json:"address"`
+	BaseAccount *struct {
+		Address string `json:"address"`
+	} `json:"base_account"` // module account
+}
+
+type GenesisBankState struct {
+	Balances []struct {
+		Address string `json:"address"`
+	} `json:"balances"`
+	DenomMetadata []struct {
+		Base string `json:"base"`
+	} `json:"denom_metadata"`
+}
+
+type GenesisGenutilState struct {
+	GenTxs []struct {
+		Body struct {
+			Messages []GenesisGenTxMessage `json:"messages"`
+		} `json:"body"`
+	} `json:"gen_txs"`
+}
+
+// GenesisGenTxMessage is a message of gentx, only MsgCreateValidator is used.
+type GenesisGenTxMessage struct {
+	Type             string             `json:"@type"`
+	Description      GenesisDescription `json:"description"`
+	DelegatorAddress string             `json:"delegator_address"`
+	ValidatorAddress string             `json:"validator_address"`
+	PubKey           *GenesisAnyPubKey  `json:"pubkey"`
+}
+
+type GenesisStakingState struct {
+	Params *struct {
+		BondDenom string `json:"bond_denom"`
+	} `json:"params"`
+	Validators []struct {
+		OperatorAddress string             `json:"operator_address"`
+		ConsensusPubKey *GenesisAnyPubKey  `json:"consensus_pubkey"`
+		Description     GenesisDescription `json:"description"`
+	} `json:"validators"`
+}
+
+type GenesisEvmState struct {
+	Params *struct {
+		EvmDenom string `json:"evm_denom"`
+	} `json:"params"`
+}
+
+type GenesisFeemarketState struct {
+	Params *struct {
+		MinGasPrice string `json:"min_gas_price"` // decimal, in the EVM denom
+		FeeDenom    string `json:"fee_denom"`     // feemarket of Skip
+	} `json:"params"`
+}
+
+// ValidatorPubKeyTypes returns the consensus key types allowed by the consensus params,
+// CometBFT defaults to ed25519 when not set.
+func (g Genesis) ValidatorPubKeyTypes() []string {
+	params := g.ConsensusParams
+	if params == nil && g.Consensus != nil {
+		params = g.Consensus.Params
+	}
+	if params == nil || params.Validator == nil || len(params.Validator.PubKeyTypes) == 0 {
+		return []string{"ed25519"}
+	}
+	return params.Validator.PubKeyTypes
+}
+
+// AccountAddresses returns addresses of genesis accounts and balances, used to find the bech32 prefix of the chain.
+func (g Genesis) AccountAddresses() []string {
+	var addresses []string
+	if g.AppState == nil {
+		return nil
+	}
+	if g.AppState.Auth != nil {
+		for _, account := range g.AppState.Auth.Accounts {
+			if account.Address != "" {
+				addresses = append(addresses, account.Address)
+			} else if account.BaseAccount != nil && account.BaseAccount.Address != "" {
+				addresses = append(addresses, account.BaseAccount.Address)
+			}
+		}
+	}
+	if g.AppState.Bank != nil {
+		for _, balance := range g.AppState.Bank.Balances {
+			if balance.Address != "" {
+				addresses = append(addresses, balance.Address)
+			}
+		}
+	}
+	return addresses
+}
+
+// InitialValidators returns the initial validator set of CometBFT.
+func (g Genesis) InitialValidators() []GenesisValidator {
+	if len(g.Validators) == 0 && g.Consensus != nil {
+		return g.Consensus.Validators
+	}
+	return g.Validators
+}
+
+// FeeDenoms returns denoms which can pay fees, from bank metadata, bond denom of staking, EVM denom and fee denom of feemarket.
+func (g Genesis) FeeDenoms() []string {
+	if g.AppState == nil {
+		return nil
+	}
+	var denoms []string
+	add := func(denom string) {
+		if denom == "" {
+			return
+		}
+		for _, existing := range denoms {
+			if existing == denom {
+				return
+			}
+		}
+		denoms = append(denoms, denom)
+	}
+	if g.AppState.Bank != nil {
+		for _, metadata := range g.AppState.Bank.DenomMetadata {
+			add(metadata.Base)
+		}
+	}
+	if g.AppState.Staking != nil && g.AppState.Staking.Params != nil {
+		add(g.AppState.Staking.Params.BondDenom)
+	}
+	add(g.EvmDenom())
+	if g.AppState.Feemarket != nil && g.AppState.Feemarket.Params != nil {
+		add(g.AppState.Feemarket.Params.FeeDenom)
+	}
+	return denoms
+}
+
+// EvmDenom returns evm_denom of the EVM module, empty for non-EVM chains.
+func (g Genesis) EvmDenom() string {
+	if g.AppState == nil || g.AppState.Evm == nil || g.AppState.Evm.Params == nil {
+		return ""
+	}
+	return g.AppState.Evm.Params.EvmDenom
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PrivValidatorState is the content of data/priv_validator_state.json, the last signed height/round/step.
+type PrivValidatorState struct {
+	Height    string `json:"height"`
+	Round     int    `json:"round"`
+	Step      int    `json:"step"`
+	Signature string `json:"signature"`
+	SignBytes string `json:"signbytes"`
+}
+
+func (s PrivValidatorState) IsEmpty() bool {
+	return s.Height == "0" && s.Round == 0 && s.Step == 0 && s.Signature == "" && s.SignBytes == ""
+}
+
+func (s PrivValidatorState) HeightInt() (int64, error) {
+	return strconv.ParseInt(s.Height, 10, 64)
+}
+
+// Compare returns -1, 0 or 1 if the height/round/step is lower, equals or higher than the other.
+func (s PrivValidatorState) Compare(other PrivValidatorState) (int, error) {
+	height, err := s.HeightInt()
+	if err != nil {
+		return 0, fmt.Errorf("invalid height %q", s.Height)
+	}
+	otherHeight, err := other.HeightInt()
+	if err != nil {
+		return 0, fmt.Errorf("invalid height %q", other.Height)
+	}
+
+	for _, pair := range [][2]int64{{height, otherHeight}, {int64(s.Round), int64(other.Round)}, {int64(s.Step), int64(other.Step)}} {
+		if pair[0] < pair[1] {
+			return -1, nil
+		}
+		if pair[0] > pair[1] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s PrivValidatorState) String() string {
+	return fmt.Sprintf("height=%s round=%d step=%d", s.Height, s.Round, s.Step)
+}
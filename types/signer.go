@@ -0,0 +1,58 @@
+package types
+
+// TmkmsToml is the config file of tmkms, only the parts used for validation & generation.
+type TmkmsToml struct {
+	Chain     []TmkmsChain               `toml:"chain"`
+	Providers map[string][]TmkmsProvider `toml:"providers"`
+	Validator []TmkmsValidator           `toml:"validator"`
+}
+
+type TmkmsChain struct {
+	Id        string          `toml:"id"`
+	KeyFormat *TmkmsKeyFormat `toml:"key_format,inline"`
+	StateFile string          `toml:"state_file"`
+}
+
+type TmkmsKeyFormat struct {
+	Type               string `toml:"type"`
+	AccountKeyPrefix   string `toml:"account_key_prefix,omitempty"`
+	ConsensusKeyPrefix string `toml:"consensus_key_prefix,omitempty"`
+}
+
+type TmkmsProvider struct {
+	ChainIds []string `toml:"chain_ids"`
+	KeyType  string   `toml:"key_type,omitempty"`
+	Path     string   `toml:"path,omitempty"`
+}
+
+type TmkmsValidator struct {
+	ChainId         string `toml:"chain_id"`
+	Addr            string `toml:"addr"`
+	SecretKey       string `toml:"secret_key"`
+	ProtocolVersion string `toml:"protocol_version"`
+	Reconnect       bool   `toml:"reconnect"`
+}
+
+// HorcruxConfig is the config.yaml of horcrux.
+type HorcruxConfig struct {
+	SignMode      string                `yaml:"signMode"`
+	ThresholdMode *HorcruxThresholdMode `yaml:"thresholdMode,omitempty"`
+	ChainNodes    []HorcruxChainNode    `yaml:"chainNodes"`
+	DebugAddr     string                `yaml:"debugAddr,omitempty"`
+}
+
+type HorcruxThresholdMode struct {
+	Threshold   int               `yaml:"threshold"`
+	Cosigners   []HorcruxCosigner `yaml:"cosigners"`
+	GrpcTimeout string            `yaml:"grpcTimeout"`
+	RaftTimeout string            `yaml:"raftTimeout"`
+}
+
+type HorcruxCosigner struct {
+	ShardId int    `yaml:"shardID"`
+	P2pAddr string `yaml:"p2pAddr"`
+}
+
+type HorcruxChainNode struct {
+	PrivValAddr string `yaml:"privValAddr"`
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AptConfSetting is a scalar setting of apt.conf, key is the full path like Unattended-Upgrade::Automatic-Reboot.
+type AptConfSetting struct {
+	Key   string
+	Value string
+	Line  int // 1-based
+}
+
+// ReadAptConf reads the scalar settings of an apt.conf file in order, both the flat form `A::B "v";`
+// and the nested form `A { B "v"; };` are supported. List items and #clear/#include directives are skipped.
+func ReadAptConf(filePath string) ([]AptConfSetting, error) {
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAptConf(string(bz))
+}
+
+// ParseAptConf parses the content of an apt.conf file, see ReadAptConf.
+func ParseAptConf(content string) ([]AptConfSetting, error) {
+	tokens, err := tokenizeAptConf(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings []AptConfSetting
+	var scopes []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.text == ";" && !token.quoted:
+			continue
+		case token.text == "}" && !token.quoted:
+			if len(scopes) == 0 {
+				return nil, fmt.Errorf("unexpected } at line %d", token.line)
+			}
+			scopes = scopes[:len(scopes)-1]
+			continue
+		case token.quoted:
+			// list item, e.g. "origin=Debian"; within Origins-Pattern
+			continue
+		}
+
+		// key, then { or value
+		if i+1 >= len(tokens) {
+			return nil, fmt.Errorf("unexpected end after %s at line %d", token.text, token.line)
+		}
+		next := tokens[i+1]
+		if next.text == "{" && !next.quoted {
+			scopes = append(scopes, token.text)
+			i++
+			continue
+		}
+		if next.text == ";" && !next.quoted {
+			// empty value
+			i++
+			continue
+		}
+		if !next.quoted {
+			return nil, fmt.Errorf("expected quoted value of %s at line %d", token.text, token.line)
+		}
+		settings = append(settings, AptConfSetting{
+			Key:   strings.Join(append(append([]string{}, scopes...), token.text), "::"),
+			Value: next.text,
+			Line:  token.line,
+		})
+		i++
+	}
+	if len(scopes) > 0 {
+		return nil, fmt.Errorf("missing } of %s", strings.Join(scopes, "::"))
+	}
+	return settings, nil
+}
+
+type aptConfToken struct {
+	text   string
+	quoted bool
+	line   int
+}
+
+// tokenizeAptConf splits into words, quoted strings and {};, comments are removed.
+func tokenizeAptConf(content string) ([]aptConfToken, error) {
+	var tokens []aptConfToken
+	line := 1
+	atLineStart := true
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			atLineStart = true
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			continue
+		case c == '#' && atLineStart:
+			// comment, or #clear/#include directive
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case c == '/' && strings.HasPrefix(content[i:], "//"):
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case c == '/' && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at line %d", line)
+			}
+			line += strings.Count(content[i:i+2+end], "\n")
+			i += 2 + end + 1
+			continue
+		}
+
+		atLineStart = false
+		switch c {
+		case '{', '}', ';':
+			tokens = append(tokens, aptConfToken{text: string(c), line: line})
+		case '"':
+			end := strings.IndexByte(content[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at line %d", line)
+			}
+			tokens = append(tokens, aptConfToken{text: content[i+1 : i+1+end], quoted: true, line: line})
+			i += 1 + end
+		default:
+			start := i
+			for i < len(content) && !strings.ContainsRune(" \t\r\n{};\"", rune(content[i])) {
+				i++
+			}
+			tokens = append(tokens, aptConfToken{text: content[start:i], line: line})
+			i--
+		}
+	}
+	return tokens, nil
+}
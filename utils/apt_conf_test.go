@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseAptConf(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // key=value@line
+		wantErr bool
+	}{
+		{
+			name:    "flat",
+			content: "APT::Periodic::Update-Package-Lists \"1\";\nAPT::Periodic::Unattended-Upgrade \"1\";\n",
+			want:    []string{"APT::Periodic::Update-Package-Lists=1@1", "APT::Periodic::Unattended-Upgrade=1@2"},
+		},
+		{
+			name: "nested",
+			content: `// comment
+Unattended-Upgrade {
+	Origins-Pattern {
+		"origin=Debian,codename=${distro_codename},label=Debian-Security";
+	};
+	Automatic-Reboot "true";
+	Automatic-Reboot-Time "02:00";
+};`,
+			want: []string{"Unattended-Upgrade::Automatic-Reboot=true@6", "Unattended-Upgrade::Automatic-Reboot-Time=02:00@7"},
+		},
+		{
+			name:    "one line block",
+			content: `APT::Periodic { Unattended-Upgrade "1"; }; Unattended-Upgrade { Automatic-Reboot "true"; };`,
+			want:    []string{"APT::Periodic::Unattended-Upgrade=1@1", "Unattended-Upgrade::Automatic-Reboot=true@1"},
+		},
+		{
+			name:    "comments",
+			content: "#clear Unattended-Upgrade::Origins-Pattern;\n/* Unattended-Upgrade::Automatic-Reboot \"true\";\n*/\n// Unattended-Upgrade::Automatic-Reboot \"true\";\nUnattended-Upgrade::Mail \"root\"; // trailing\n",
+			want:    []string{"Unattended-Upgrade::Mail=root@5"},
+		},
+		{
+			name:    "value with slashes",
+			content: `Acquire::http::Proxy "http://proxy:3142/";`,
+			want:    []string{"Acquire::http::Proxy=http://proxy:3142/@1"},
+		},
+		{name: "unterminated block", content: `Unattended-Upgrade { Automatic-Reboot "true";`, wantErr: true},
+		{name: "unterminated string", content: `Unattended-Upgrade::Automatic-Reboot "true;`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := ParseAptConf(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %v", settings)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, setting := range settings {
+				got = append(got, fmt.Sprintf("%s=%s@%d", setting.Key, setting.Value, setting.Line))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("settings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"strconv"
+	"syscall"
+)
+
+// ErrDbLocked is returned when the database is locked by another process, e.g. the running node.
+var ErrDbLocked = errors.New("database is locked by another process")
+
+// blockStoreStateKey is the key where CometBFT block store keeps its base & height.
+var blockStoreStateKey = []byte("blockStore")
+
+// BlockStoreState is the range of blocks available in the block store.
+type BlockStoreState struct {
+	Base   int64
+	Height int64
+}
+
+// ReadBlockStoreState opens the block store read-only and reads its base & height.
+// Supported backends are goleveldb and pebble, ErrDbLocked is returned while the database is in use by the node.
+func ReadBlockStoreState(blockStoreDir string, backend string) (*BlockStoreState, error) {
+	var value []byte
+	var found bool
+	var err error
+
+	switch backend {
+	case DbBackendGoLevelDb:
+		value, found, err = readGoLevelDb(blockStoreDir, blockStoreStateKey)
+	case DbBackendPebble:
+		value, found, err = readPebbleDb(blockStoreDir, blockStoreStateKey)
+	default:
+		return nil, fmt.Errorf("unsupported database backend %q", backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found || len(value) == 0 {
+		// fresh block store
+		return &BlockStoreState{}, nil
+	}
+
+	return decodeBlockStoreState(value)
+}
+
+// decodeBlockStoreState decodes the block store state, which is protobuf encoded since Tendermint v0.34,
+// or JSON encoded in older versions.
+func decodeBlockStoreState(value []byte) (*BlockStoreState, error) {
+	if value[0] == '{' {
+		var state struct {
+			Base   string `json:"base"`
+			Height string `json:"height"`
+		}
+		if err := json.Unmarshal(value, &state); err != nil {
+			return nil, errors.Wrap(err, "failed to decode JSON block store state")
+		}
+		base, _ := strconv.ParseInt(state.Base, 10, 64)
+		height, err := strconv.ParseInt(state.Height, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse height of block store state")
+		}
+		return &BlockStoreState{Base: base, Height: height}, nil
+	}
+
+	// message BlockStoreState { int64 base = 1; int64 height = 2; }
+	state := &BlockStoreState{}
+	for len(value) > 0 {
+		tag, n := binary.Uvarint(value)
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed block store state")
+		}
+		value = value[n:]
+
+		fieldNumber, wireType := tag>>3, tag&0x7
+		if wireType != 0 {
+			return nil, fmt.Errorf("unexpected wire type %d of field %d in block store state", wireType, fieldNumber)
+		}
+
+		fieldValue, n := binary.Uvarint(value)
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed block store state")
+		}
+		value = value[n:]
+
+		switch fieldNumber {
+		case 1:
+			state.Base = int64(fieldValue)
+		case 2:
+			state.Height = int64(fieldValue)
+		}
+	}
+
+	return state, nil
+}
+
+func readGoLevelDb(dir string, key []byte) (value []byte, found bool, err error) {
+	db, err := leveldb.OpenFile(dir, &opt.Options{
+		ReadOnly:       true,
+		ErrorIfMissing: true,
+	})
+	if isLockedErr(err) {
+		return nil, false, ErrDbLocked
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to open goleveldb")
+	}
+	defer db.Close()
+
+	value, err = db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func readPebbleDb(dir string, key []byte) (value []byte, found bool, err error) {
+	db, err := pebble.Open(dir, &pebble.Options{
+		ReadOnly:         true,
+		ErrorIfNotExists: true,
+	})
+	if isLockedErr(err) {
+		return nil, false, ErrDbLocked
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to open pebble")
+	}
+	defer db.Close()
+
+	bz, closer, err := db.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer closer.Close()
+
+	value = make([]byte, len(bz))
+	copy(value, bz)
+	return value, true, nil
+}
+
+// isLockedErr returns true if the error is caused by the lock file held by another process,
+// goleveldb uses flock and pebble uses fcntl, both fail immediately instead of waiting.
+// Permission denied is not a lock, it is returned as is.
+func isLockedErr(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK)
+}
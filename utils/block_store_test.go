@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"github.com/cockroachdb/pebble"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+// encodeBlockStoreState encodes the state as protobuf like CometBFT does.
+func encodeBlockStoreState(base, height int64) []byte {
+	var bz []byte
+	bz = binary.AppendUvarint(bz, 1<<3)
+	bz = binary.AppendUvarint(bz, uint64(base))
+	bz = binary.AppendUvarint(bz, 2<<3)
+	bz = binary.AppendUvarint(bz, uint64(height))
+	return bz
+}
+
+func TestReadBlockStoreState_GoLevelDbLocked(t *testing.T) {
+	dir := t.TempDir()
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(blockStoreStateKey, encodeBlockStoreState(1, 100), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// flock conflicts across file descriptors, even within the same process
+	if _, err := ReadBlockStoreState(dir, DbBackendGoLevelDb); !errors.Is(err, ErrDbLocked) {
+		t.Fatalf("want ErrDbLocked while the database is open, got %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	state, err := ReadBlockStoreState(dir, DbBackendGoLevelDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Base != 1 || state.Height != 100 {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
+
+func TestReadBlockStoreState_PebbleLocked(t *testing.T) {
+	dir := t.TempDir()
+	db, err := pebble.Open(dir, &pebble.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(blockStoreStateKey, encodeBlockStoreState(1, 200), pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// fcntl lock is per process, hold it from another process like the running node
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperHoldPebbleLock$")
+	cmd.Env = append(os.Environ(), "NODESC_TEST_HOLD_PEBBLE="+dir)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		_ = cmd.Process.Kill()
+		t.Fatalf("helper failed to hold the lock: %q %v", line, err)
+	}
+
+	_, err = ReadBlockStoreState(dir, DbBackendPebble)
+	_ = stdin.Close()
+	_ = cmd.Wait()
+	if !errors.Is(err, ErrDbLocked) {
+		t.Fatalf("want ErrDbLocked while the database is open, got %v", err)
+	}
+
+	state, err := ReadBlockStoreState(dir, DbBackendPebble)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Base != 1 || state.Height != 200 {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
+
+// TestHelperHoldPebbleLock is not a real test, it holds the pebble database open until stdin is closed.
+func TestHelperHoldPebbleLock(t *testing.T) {
+	dir := os.Getenv("NODESC_TEST_HOLD_PEBBLE")
+	if dir == "" {
+		t.Skip("helper process")
+	}
+	db, err := pebble.Open(dir, &pebble.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	os.Stdout.WriteString("locked\n")
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+func TestIsLockedErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "EAGAIN", err: &os.PathError{Op: "lock", Path: "LOCK", Err: syscall.EAGAIN}, want: true},
+		{name: "EWOULDBLOCK", err: errors.Wrap(syscall.EWOULDBLOCK, "failed to lock"), want: true},
+		{name: "EACCES", err: &os.PathError{Op: "open", Path: "LOCK", Err: syscall.EACCES}, want: false},
+		{name: "not exist", err: &os.PathError{Op: "open", Path: "LOCK", Err: syscall.ENOENT}, want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLockedErr(tt.err); got != tt.want {
+				t.Errorf("isLockedErr(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
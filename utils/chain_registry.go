@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+)
+
+// LoadChainRegistryChain loads chain.json of the chain id, registryPath can be a chain.json file
+// or a checkout of the cosmos chain-registry, where mainnets are at <chain>/chain.json and testnets at testnets/<chain>/chain.json.
+// Returns the chain and path of the chain.json file.
+func LoadChainRegistryChain(registryPath string, chainId string) (*types.ChainRegistryChain, string, error) {
+	_, exists, isDir, err := FileInfo(registryPath)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		return nil, "", fmt.Errorf("chain registry does not exist: %s", registryPath)
+	}
+
+	if !isDir {
+		chain, err := readChainRegistryChain(registryPath)
+		if err != nil {
+			return nil, "", err
+		}
+		if chain.ChainId != chainId {
+			return nil, "", fmt.Errorf("chain_id %s of %s does not match chain id %s of the node", chain.ChainId, registryPath, chainId)
+		}
+		return chain, registryPath, nil
+	}
+
+	var candidates []string
+	for _, pattern := range []string{
+		filepath.Join(registryPath, "*", "chain.json"),
+		filepath.Join(registryPath, "testnets", "*", "chain.json"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, "", err
+		}
+		candidates = append(candidates, matches...)
+	}
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("no chain.json found in %s, not a chain-registry checkout", registryPath)
+	}
+
+	for _, candidate := range candidates {
+		chain, err := readChainRegistryChain(candidate)
+		if err != nil {
+			// one broken file should not prevent finding the chain
+			continue
+		}
+		if chain.ChainId == chainId {
+			return chain, candidate, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("no chain.json with chain_id %s in chain registry %s", chainId, registryPath)
+}
+
+func readChainRegistryChain(filePath string) (*types.ChainRegistryChain, error) {
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var chain types.ChainRegistryChain
+	if err := json.Unmarshal(bz, &chain); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", filePath)
+	}
+	return &chain, nil
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadChainRegistryChain(t *testing.T) {
+	registry := filepath.Join("testdata", "chain-registry")
+	mainnet := filepath.Join(registry, "cosmoshub", "chain.json")
+	testnet := filepath.Join(registry, "testnets", "cosmoshubtestnet", "chain.json")
+
+	tests := []struct {
+		name         string
+		registryPath string
+		chainId      string
+		wantFile     string
+		wantErr      bool
+	}{
+		// broken/chain.json is skipped
+		{name: "mainnet in checkout", registryPath: registry, chainId: "cosmoshub-4", wantFile: mainnet},
+		{name: "testnet in checkout", registryPath: registry, chainId: "theta-testnet-001", wantFile: testnet},
+		{name: "unknown chain in checkout", registryPath: registry, chainId: "osmosis-1", wantErr: true},
+		{name: "chain.json file", registryPath: mainnet, chainId: "cosmoshub-4", wantFile: mainnet},
+		{name: "chain.json file of another chain", registryPath: mainnet, chainId: "theta-testnet-001", wantErr: true},
+		{name: "broken chain.json file", registryPath: filepath.Join(registry, "broken", "chain.json"), chainId: "broken-1", wantErr: true},
+		{name: "not a checkout", registryPath: t.TempDir(), chainId: "cosmoshub-4", wantErr: true},
+		{name: "missing", registryPath: filepath.Join(t.TempDir(), "missing"), chainId: "cosmoshub-4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, chainFilePath, err := LoadChainRegistryChain(tt.registryPath, tt.chainId)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %s", chainFilePath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to load chain registry: %v", err)
+			}
+			if chainFilePath != tt.wantFile {
+				t.Errorf("chain file = %s, want %s", chainFilePath, tt.wantFile)
+			}
+			if chain.ChainId != tt.chainId {
+				t.Errorf("chain_id = %s, want %s", chain.ChainId, tt.chainId)
+			}
+		})
+	}
+}
+
+func TestLoadChainRegistryChain_RegistrySchema(t *testing.T) {
+	chain, _, err := LoadChainRegistryChain(filepath.Join("testdata", "chain-registry", "cosmoshub", "chain.json"), "cosmoshub-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain.ChainName != "cosmoshub" || chain.Bech32Prefix != "cosmos" {
+		t.Errorf("chain_name = %s, bech32_prefix = %s", chain.ChainName, chain.Bech32Prefix)
+	}
+
+	feeToken := chain.FeeToken("uatom")
+	if feeToken == nil {
+		t.Fatal("fee token uatom is not found")
+	}
+	if feeToken.FixedMinGasPrice != "0.005" || feeToken.LowGasPrice != "0.01" || feeToken.AverageGasPrice != "0.025" || feeToken.HighGasPrice != "0.03" {
+		t.Errorf("gas prices of uatom = %+v", *feeToken)
+	}
+	if chain.FeeToken("uosmo") != nil {
+		t.Error("uosmo should not be a fee token")
+	}
+
+	if chain.Peers == nil || len(chain.Peers.Seeds) != 2 || len(chain.Peers.PersistentPeers) != 1 {
+		t.Fatalf("peers = %+v", chain.Peers)
+	}
+	if seed := chain.Peers.Seeds[0]; seed.Id != "ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0" || seed.Address != "seeds.polkachu.com:14956" {
+		t.Errorf("first seed = %+v", seed)
+	}
+
+	// the registry schema has no hash of genesis
+	if chain.Codebase == nil || chain.Codebase.Genesis == nil {
+		t.Fatal("codebase.genesis is not loaded")
+	}
+	if chain.Codebase.Genesis.GenesisUrl == "" {
+		t.Error("genesis_url is not loaded")
+	}
+	if chain.Codebase.Genesis.Sha256 != "" {
+		t.Errorf("sha256 = %s, want empty", chain.Codebase.Genesis.Sha256)
+	}
+}
+
+func TestLoadChainRegistryChain_LocalGenesisHash(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "chain.json")
+	bz := []byte(`{"chain_id": "mychain-1", "codebase": {"genesis": {"genesis_url": "https://example.com/genesis.json", "sha256": "ABCDEF"}}}`)
+	if err := os.WriteFile(filePath, bz, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, _, err := LoadChainRegistryChain(filePath, "mychain-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.Codebase.Genesis.Sha256 != "ABCDEF" {
+		t.Errorf("sha256 = %s, want ABCDEF", chain.Codebase.Genesis.Sha256)
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // used by CometBFT for secp256k1 address
+)
+
+// Amino JSON names of consensus keys, as in priv_validator_key.json.
+const (
+	PrivKeyTypeEd25519   = "tendermint/PrivKeyEd25519"
+	PubKeyTypeEd25519    = "tendermint/PubKeyEd25519"
+	PrivKeyTypeSecp256k1 = "tendermint/PrivKeySecp256k1"
+	PubKeyTypeSecp256k1  = "tendermint/PubKeySecp256k1"
+)
+
+// ConsensusKeyAbciType returns the name used in pub_key_types of consensus params.
+func ConsensusKeyAbciType(pubKeyType string) string {
+	switch pubKeyType {
+	case PubKeyTypeEd25519:
+		return "ed25519"
+	case PubKeyTypeSecp256k1:
+		return "secp256k1"
+	default:
+		return ""
+	}
+}
+
+// ConsensusKeyProtoType returns the type URL of the public key packed in Any, as in gentx and staking genesis.
+func ConsensusKeyProtoType(pubKeyType string) string {
+	switch pubKeyType {
+	case PubKeyTypeEd25519:
+		return "/cosmos.crypto.ed25519.PubKey"
+	case PubKeyTypeSecp256k1:
+		return "/cosmos.crypto.secp256k1.PubKey"
+	default:
+		return ""
+	}
+}
+
+// DeriveConsensusPubKey returns the public key of the consensus private key.
+func DeriveConsensusPubKey(privKeyType string, privKey []byte) (pubKeyType string, pubKey []byte, err error) {
+	switch privKeyType {
+	case PrivKeyTypeEd25519:
+		if len(privKey) != ed25519.PrivateKeySize {
+			return "", nil, fmt.Errorf("ed25519 private key must be %d bytes, got %d", ed25519.PrivateKeySize, len(privKey))
+		}
+		// private key is seed followed by public key, the public key part is used when signing so both must agree
+		pubKey = ed25519.NewKeyFromSeed(privKey[:ed25519.SeedSize]).Public().(ed25519.PublicKey)
+		if !bytes.Equal(pubKey, privKey[ed25519.SeedSize:]) {
+			return "", nil, fmt.Errorf("public key part of the ed25519 private key does not match its seed")
+		}
+		return PubKeyTypeEd25519, pubKey, nil
+	case PrivKeyTypeSecp256k1:
+		if len(privKey) != secp256k1.PrivKeyBytesLen {
+			return "", nil, fmt.Errorf("secp256k1 private key must be %d bytes, got %d", secp256k1.PrivKeyBytesLen, len(privKey))
+		}
+		return PubKeyTypeSecp256k1, secp256k1.PrivKeyFromBytes(privKey).PubKey().SerializeCompressed(), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported consensus key type %s", privKeyType)
+	}
+}
+
+// NodeId returns the node ID of the node key, the address of its public key in lower case hex.
+func NodeId(privKeyType string, privKey []byte) (string, error) {
+	if privKeyType != PrivKeyTypeEd25519 {
+		return "", fmt.Errorf("node key must be %s, got %s", PrivKeyTypeEd25519, privKeyType)
+	}
+	pubKeyType, pubKey, err := DeriveConsensusPubKey(privKeyType, privKey)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(ConsensusAddress(pubKeyType, pubKey)), nil
+}
+
+// ConsensusAddress returns the address of the consensus public key,
+// first 20 bytes of SHA256 for ed25519, RIPEMD160 of SHA256 for secp256k1.
+func ConsensusAddress(pubKeyType string, pubKey []byte) []byte {
+	hash := sha256.Sum256(pubKey)
+	if pubKeyType == PubKeyTypeSecp256k1 {
+		hasher := ripemd160.New()
+		hasher.Write(hash[:])
+		return hasher.Sum(nil)
+	}
+	return hash[:20]
+}
+
+// Bech32Encode encodes the bytes into bech32 address with the given human-readable part.
+func Bech32Encode(hrp string, bz []byte) (string, error) {
+	converted, err := bech32.ConvertBits(bz, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(hrp, converted)
+}
+
+// Bech32Hrp returns the human-readable part of the bech32 address.
+func Bech32Hrp(address string) (string, error) {
+	hrp, _, err := bech32.Decode(address, 1023)
+	if err != nil {
+		return "", err
+	}
+	return hrp, nil
+}
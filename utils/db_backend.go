@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Database backends, named as in db_backend of config.toml
+const (
+	DbBackendGoLevelDb = "goleveldb"
+	DbBackendCLevelDb  = "cleveldb"
+	DbBackendPebble    = "pebbledb"
+	DbBackendRocksDb   = "rocksdb"
+	DbBackendBadger    = "badgerdb"
+	DbBackendBoltDb    = "boltdb"
+)
+
+// DetectDbBackend detects backend of a database from the signature of its files.
+// Returns empty if the backend can not be recognized, e.g. empty directory.
+//
+// Note: goleveldb and cleveldb share the same on-disk format, both are reported as goleveldb.
+func DetectDbBackend(dbPath string) (string, error) {
+	fi, err := os.Stat(dbPath)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		// BoltDB is a single file database
+		if isBoltDbFile(dbPath) {
+			return DbBackendBoltDb, nil
+		}
+		return "", nil
+	}
+
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		return "", err
+	}
+
+	var hasLdbFile, hasSstFile, hasLevelManifest, hasBadgerManifest, hasVlogFile, hasKeyRegistry bool
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasPrefix(name, "OPTIONS-"):
+			// both pebble and rocksdb write options file, with different version keys
+			bz, err := os.ReadFile(filepath.Join(dbPath, name))
+			if err != nil {
+				return "", err
+			}
+			if strings.Contains(string(bz), "pebble_version") {
+				return DbBackendPebble, nil
+			}
+			if strings.Contains(string(bz), "rocksdb_version") {
+				return DbBackendRocksDb, nil
+			}
+		case name == "KEYREGISTRY":
+			hasKeyRegistry = true
+		case name == "MANIFEST":
+			hasBadgerManifest = true
+		case strings.HasPrefix(name, "MANIFEST-"):
+			hasLevelManifest = true
+		case strings.HasSuffix(name, ".vlog"):
+			hasVlogFile = true
+		case strings.HasSuffix(name, ".ldb"):
+			hasLdbFile = true
+		case strings.HasSuffix(name, ".sst"):
+			hasSstFile = true
+		}
+	}
+
+	if hasKeyRegistry || (hasBadgerManifest && hasVlogFile) {
+		return DbBackendBadger, nil
+	}
+
+	if hasLevelManifest {
+		if fileHeadContains(filepath.Join(dbPath, "LOG"), "RocksDB version") {
+			return DbBackendRocksDb, nil
+		}
+		if hasLdbFile || !hasSstFile {
+			return DbBackendGoLevelDb, nil
+		}
+		// sst files without options file, most likely rocksdb
+		return DbBackendRocksDb, nil
+	}
+
+	return "", nil
+}
+
+// isBoltDbFile checks the magic number 0xED0CDAED of BoltDB at the meta page.
+func isBoltDbFile(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	// page header is 16 bytes, magic is the first field of meta
+	buf := make([]byte, 20)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		return false
+	}
+	magic := uint32(buf[16]) | uint32(buf[17])<<8 | uint32(buf[18])<<16 | uint32(buf[19])<<24
+	return magic == 0xED0CDAED
+}
+
+// fileHeadContains checks if any of the first few lines of the file contains the text.
+func fileHeadContains(filePath string, contains string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < 5 && scanner.Scan(); i++ {
+		if strings.Contains(scanner.Text(), contains) {
+			return true
+		}
+	}
+	return false
+}
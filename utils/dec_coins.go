@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// DecCoinPrecision is the number of decimal places of LegacyDec of the Cosmos SDK.
+const DecCoinPrecision = 18
+
+// regexDecCoin matches a DecCoin like 0.025uatom, denom rule follows the Cosmos SDK.
+var regexDecCoin = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?|\.[0-9]+)\s*([a-zA-Z][a-zA-Z0-9/:._-]{2,127})$`)
+
+// DecCoin is an entry of a DecCoins list like minimum-gas-prices.
+type DecCoin struct {
+	Amount string
+	Denom  string
+}
+
+// Rat returns the amount as a rational number, amount is validated by ParseDecCoins.
+func (c DecCoin) Rat() *big.Rat {
+	rat, _ := new(big.Rat).SetString(c.Amount)
+	return rat
+}
+
+func (c DecCoin) String() string {
+	return c.Amount + c.Denom
+}
+
+// ParseDecCoins parses comma-separated DecCoins, e.g. 0.025uatom,0.1ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2.
+func ParseDecCoins(coins string) ([]DecCoin, error) {
+	coins = strings.TrimSpace(coins)
+	if coins == "" {
+		return nil, nil
+	}
+
+	var decCoins []DecCoin
+	for _, coin := range strings.Split(coins, ",") {
+		coin = strings.TrimSpace(coin)
+		matches := regexDecCoin.FindStringSubmatch(coin)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid DecCoin %q, must be <amount><denom> like 0.025uatom", coin)
+		}
+		if _, decimals, found := strings.Cut(matches[1], "."); found && len(decimals) > DecCoinPrecision {
+			return nil, fmt.Errorf("amount of %q has more than %d decimal places", coin, DecCoinPrecision)
+		}
+		decCoins = append(decCoins, DecCoin{
+			Amount: matches[1],
+			Denom:  matches[2],
+		})
+	}
+	return decCoins, nil
+}
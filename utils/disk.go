@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// DiskUsage is the usage of the filesystem backing a path.
+type DiskUsage struct {
+	TotalBytes  uint64
+	FreeBytes   uint64 // available to unprivileged users
+	TotalInodes uint64
+	FreeInodes  uint64
+}
+
+func (u DiskUsage) FreePercent() float64 {
+	if u.TotalBytes == 0 {
+		return 0
+	}
+	return float64(u.FreeBytes) * 100 / float64(u.TotalBytes)
+}
+
+// UsedInodesPercent returns 0 if the filesystem does not report inodes, e.g. btrfs.
+func (u DiskUsage) UsedInodesPercent() float64 {
+	if u.TotalInodes == 0 {
+		return 0
+	}
+	return float64(u.TotalInodes-u.FreeInodes) * 100 / float64(u.TotalInodes)
+}
+
+// DirSize returns the total size of all regular files under the directory, symlinks within are not followed.
+// The directory itself can be a symlink, e.g. data symlinked to another disk.
+func DirSize(dir string) (int64, error) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// GetDiskUsage is not supported on this platform.
+func GetDiskUsage(_ string) (DiskUsage, error) {
+	return DiskUsage{}, fmt.Errorf("disk usage is not supported on %s", runtime.GOOS)
+}
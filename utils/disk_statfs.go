@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package utils
+
+import "syscall"
+
+// GetDiskUsage returns usage of the filesystem which backs the path.
+func GetDiskUsage(path string) (DiskUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return DiskUsage{}, err
+	}
+
+	blockSize := uint64(stat.Bsize)
+	return DiskUsage{
+		TotalBytes:  uint64(stat.Blocks) * blockSize,
+		FreeBytes:   uint64(stat.Bavail) * blockSize,
+		TotalInodes: uint64(stat.Files),
+		FreeInodes:  uint64(stat.Ffree),
+	}, nil
+}
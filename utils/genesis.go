@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bcdevtools/node-setup-check/types"
+	"github.com/pkg/errors"
+	"os"
+)
+
+// ReadGenesisChainId reads chain_id of genesis.json, decoding as a stream since genesis can be hundreds of MB.
+func ReadGenesisChainId(genesisFilePath string) (string, error) {
+	f, err := os.Open(genesisFilePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return "", fmt.Errorf("genesis is not a JSON object: %s", genesisFilePath)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to decode %s", genesisFilePath)
+		}
+		if token == "chain_id" {
+			var chainId string
+			if err := decoder.Decode(&chainId); err != nil {
+				return "", errors.Wrapf(err, "failed to decode chain_id of %s", genesisFilePath)
+			}
+			return chainId, nil
+		}
+
+		// skip value of other keys
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return "", errors.Wrapf(err, "failed to decode %s", genesisFilePath)
+		}
+	}
+
+	return "", fmt.Errorf("chain_id is missing in %s", genesisFilePath)
+}
+
+// ReadGenesis reads the part of genesis.json described by types.Genesis, keys which are not used are skipped.
+func ReadGenesis(genesisFilePath string) (*types.Genesis, error) {
+	f, err := os.Open(genesisFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var genesis types.Genesis
+	fields := map[string]any{
+		"chain_id":         &genesis.ChainId,
+		"consensus_params": &genesis.ConsensusParams,
+		"consensus":        &genesis.Consensus,
+		"validators":       &genesis.Validators,
+		"app_state":        &genesis.AppState,
+	}
+
+	decoder := json.NewDecoder(f)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("genesis is not a JSON object: %s", genesisFilePath)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s", genesisFilePath)
+		}
+		key, _ := token.(string)
+		if field, found := fields[key]; found {
+			if err := decoder.Decode(field); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode %s of %s", key, genesisFilePath)
+			}
+			continue
+		}
+
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %s", genesisFilePath)
+		}
+	}
+
+	return &genesis, nil
+}
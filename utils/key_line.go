@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var regexpSectionHeader = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*]\s*(#.*)?$`)
+
+// FindKeyLine returns the 1-based line number where the dotted key is defined, or 0 if not found.
+//
+// Supports TOML and INI-like files (systemd unit), where the part before the last dot is the section,
+// e.g. "grpc.enable" or "Service.Restart".
+// For JSON files, only the last part of the key is looked up, e.g. "priv_key.type" is found by "type".
+func FindKeyLine(content []byte, dottedKey string) int {
+	if dottedKey == "" {
+		return 0
+	}
+
+	section := ""
+	name := dottedKey
+	if idx := strings.LastIndex(dottedKey, "."); idx >= 0 {
+		section = dottedKey[:idx]
+		name = dottedKey[idx+1:]
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		regexpJsonKey := regexp.MustCompile(`"` + regexp.QuoteMeta(name) + `"\s*:`)
+		return findFirstLine(content, func(line string) bool {
+			return regexpJsonKey.MatchString(line)
+		})
+	}
+
+	regexpKey := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(name) + `"?\s*=`)
+	currentSection := ""
+	return findFirstLine(content, func(line string) bool {
+		if matches := regexpSectionHeader.FindStringSubmatch(line); matches != nil {
+			currentSection = matches[1]
+			return false
+		}
+		return currentSection == section && regexpKey.MatchString(line)
+	})
+}
+
+func findFirstLine(content []byte, match func(line string) bool) int {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		if match(scanner.Text()) {
+			return lineNo
+		}
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// OwnershipMismatch is the files within a tree which are not owned by the expected user or group.
+type OwnershipMismatch struct {
+	WrongOwner []string
+	WrongGroup []string
+}
+
+// FindOwnershipMismatch walks the tree and finds the files which are not owned by the expected uid or gid.
+// Symlinks are not followed, ownership of the symlinks themselves does not matter.
+func FindOwnershipMismatch(root string, uid, gid uint32) (*OwnershipMismatch, error) {
+	mismatch := &OwnershipMismatch{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fileUid, fileGid, err := fileInfoOwner(info)
+		if err != nil {
+			return err
+		}
+		if fileUid != uid {
+			mismatch.WrongOwner = append(mismatch.WrongOwner, path)
+		}
+		if fileGid != gid {
+			mismatch.WrongGroup = append(mismatch.WrongGroup, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mismatch, nil
+}
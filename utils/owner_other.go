@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !freebsd
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// FileOwner is not supported on this platform.
+func FileOwner(_ string) (uid, gid uint32, err error) {
+	return 0, 0, fmt.Errorf("file ownership is not supported on %s", runtime.GOOS)
+}
+
+func fileInfoOwner(_ os.FileInfo) (uid, gid uint32, err error) {
+	return 0, 0, fmt.Errorf("file ownership is not supported on %s", runtime.GOOS)
+}
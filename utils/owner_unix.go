@@ -0,0 +1,26 @@
+//go:build linux || darwin || freebsd
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// FileOwner returns uid & gid of the file, symlinks are followed.
+func FileOwner(path string) (uid, gid uint32, err error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return fileInfoOwner(fi)
+}
+
+func fileInfoOwner(fi os.FileInfo) (uid, gid uint32, err error) {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("ownership is not available for %s", fi.Name())
+	}
+	return stat.Uid, stat.Gid, nil
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SystemUser is an entry of /etc/passwd.
+type SystemUser struct {
+	Name string
+	Uid  uint32
+	Gid  uint32 // primary group
+	Home string
+}
+
+// SystemGroup is an entry of /etc/group.
+type SystemGroup struct {
+	Name string
+	Gid  uint32
+}
+
+// SystemAccounts is users & groups read from /etc/passwd and /etc/group.
+type SystemAccounts struct {
+	Users  []SystemUser
+	Groups []SystemGroup
+}
+
+// ReadSystemAccounts reads users & groups from passwd and group files under etcRoot.
+// Accounts from NSS sources other than files, like LDAP, are not available.
+func ReadSystemAccounts(etcRoot string) (*SystemAccounts, error) {
+	accounts := &SystemAccounts{}
+
+	// name:password:uid:gid:gecos:home:shell
+	err := readColonSeparatedFile(filepath.Join(etcRoot, "passwd"), 7, func(fields []string) {
+		uid, err1 := strconv.ParseUint(fields[2], 10, 32)
+		gid, err2 := strconv.ParseUint(fields[3], 10, 32)
+		if err1 != nil || err2 != nil {
+			return
+		}
+		accounts.Users = append(accounts.Users, SystemUser{
+			Name: fields[0],
+			Uid:  uint32(uid),
+			Gid:  uint32(gid),
+			Home: fields[5],
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// name:password:gid:members
+	err = readColonSeparatedFile(filepath.Join(etcRoot, "group"), 3, func(fields []string) {
+		gid, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return
+		}
+		accounts.Groups = append(accounts.Groups, SystemGroup{
+			Name: fields[0],
+			Gid:  uint32(gid),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// LookupUser finds user by name or numeric uid.
+func (a *SystemAccounts) LookupUser(nameOrUid string) *SystemUser {
+	uid, errParseUid := strconv.ParseUint(nameOrUid, 10, 32)
+	for i, user := range a.Users {
+		if user.Name == nameOrUid || (errParseUid == nil && user.Uid == uint32(uid)) {
+			return &a.Users[i]
+		}
+	}
+	return nil
+}
+
+// UserName returns name of the uid, or the uid itself if not found.
+func (a *SystemAccounts) UserName(uid uint32) string {
+	for _, user := range a.Users {
+		if user.Uid == uid {
+			return user.Name
+		}
+	}
+	return strconv.FormatUint(uint64(uid), 10)
+}
+
+// GroupName returns name of the gid, or the gid itself if not found.
+func (a *SystemAccounts) GroupName(gid uint32) string {
+	for _, group := range a.Groups {
+		if group.Gid == gid {
+			return group.Name
+		}
+	}
+	return strconv.FormatUint(uint64(gid), 10)
+}
+
+func readColonSeparatedFile(filePath string, minimumFields int, callback func(fields []string)) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < minimumFields {
+			continue
+		}
+		callback(fields)
+	}
+	return scanner.Err()
+}
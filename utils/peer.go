@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var regexHostname = regexp.MustCompile(`^[a-zA-Z\d]([a-zA-Z\d-]{0,61}[a-zA-Z\d])?(\.[a-zA-Z\d]([a-zA-Z\d-]{0,61}[a-zA-Z\d])?)*\.?$`)
+
+// Peer is an entry of seeds or persistent_peers, in format <node ID>@<host>:<port>.
+type Peer struct {
+	Id   string
+	Host string
+	Port int
+}
+
+// Address returns host:port of the peer, IPv6 in brackets.
+func (p Peer) Address() string {
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+func (p Peer) String() string {
+	return p.Id + "@" + p.Address()
+}
+
+// IP returns the IP of the peer, nil for hostnames.
+func (p Peer) IP() net.IP {
+	return net.ParseIP(p.Host)
+}
+
+// SplitPeerList splits comma-separated list, empty entries are ignored like CometBFT does.
+func SplitPeerList(list string) []string {
+	var entries []string
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ParsePeer parses an entry of seeds or persistent_peers.
+func ParsePeer(entry string) (*Peer, error) {
+	id, address, found := strings.Cut(entry, "@")
+	if !found {
+		return nil, fmt.Errorf("missing node ID, must be <node ID>@<host>:<port>")
+	}
+	if bz, err := hex.DecodeString(id); err != nil || len(bz) != 20 {
+		return nil, fmt.Errorf("node ID %q must be 40 hex characters", id)
+	}
+	if strings.ToLower(id) != id {
+		return nil, fmt.Errorf("node ID %q must be in lower case", id)
+	}
+
+	if !strings.HasPrefix(address, "[") && strings.Count(address, ":") > 1 {
+		return nil, fmt.Errorf("IPv6 address must be in brackets, e.g. [2001:db8::1]:26656")
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host is missing")
+	}
+	if strings.HasPrefix(address, "[") && (net.ParseIP(host) == nil || !strings.Contains(host, ":")) {
+		return nil, fmt.Errorf("%q in brackets is not an IPv6 address", host)
+	}
+	if net.ParseIP(host) == nil && !regexHostname.MatchString(host) {
+		return nil, fmt.Errorf("invalid hostname %q", host)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("port %q is out of range 1-65535", portStr)
+	}
+
+	return &Peer{
+		Id:   id,
+		Host: host,
+		Port: port,
+	}, nil
+}
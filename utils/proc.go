@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultProcRoot is where procfs is mounted, overridable to read from a fixture tree.
+const DefaultProcRoot = "/proc"
+
+// MemInfo is the memory information read from /proc/meminfo, in bytes.
+type MemInfo struct {
+	MemTotal  uint64
+	SwapTotal uint64
+}
+
+// Mount is an entry of /proc/mounts.
+type Mount struct {
+	Device     string
+	MountPoint string
+	FsType     string
+	Options    []string
+}
+
+func (m Mount) IsReadOnly() bool {
+	for _, option := range m.Options {
+		if option == "ro" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadMemInfo reads total memory & swap from /proc/meminfo.
+func ReadMemInfo(procRoot string) (*MemInfo, error) {
+	filePath := filepath.Join(procRoot, "meminfo")
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	memInfo := &MemInfo{}
+	var foundMemTotal bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// MemTotal:       32768000 kB
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) < 1 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s in %s", name, filePath)
+		}
+		if len(fields) > 1 && strings.EqualFold(fields[1], "kB") {
+			n *= 1024
+		}
+
+		switch name {
+		case "MemTotal":
+			memInfo.MemTotal = n
+			foundMemTotal = true
+		case "SwapTotal":
+			memInfo.SwapTotal = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !foundMemTotal {
+		return nil, fmt.Errorf("MemTotal is missing in %s", filePath)
+	}
+
+	return memInfo, nil
+}
+
+// CountCpuCores counts the logical processors listed in /proc/cpuinfo.
+func CountCpuCores(procRoot string) (int, error) {
+	filePath := filepath.Join(procRoot, "cpuinfo")
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var count int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, _, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(name) == "processor" {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no processor found in %s", filePath)
+	}
+
+	return count, nil
+}
+
+// ReadFileMax reads the system-wide limit of open files from /proc/sys/fs/file-max.
+func ReadFileMax(procRoot string) (uint64, error) {
+	filePath := filepath.Join(procRoot, "sys", "fs", "file-max")
+	bz, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	fileMax, err := strconv.ParseUint(strings.TrimSpace(string(bz)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s", filePath)
+	}
+	return fileMax, nil
+}
+
+// IsSwapEnabled checks if any swap device or file is active, by reading /proc/swaps.
+func IsSwapEnabled(procRoot string) (bool, error) {
+	bz, err := os.ReadFile(filepath.Join(procRoot, "swaps"))
+	if err != nil {
+		return false, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(bz)), "\n")
+	// first line is the header
+	return len(lines) > 1, nil
+}
+
+// ReadMounts reads the mounted filesystems from /proc/mounts.
+func ReadMounts(procRoot string) ([]Mount, error) {
+	filePath := filepath.Join(procRoot, "mounts")
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []Mount
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, Mount{
+			Device:     fields[0],
+			MountPoint: unescapeMountField(fields[1]),
+			FsType:     fields[2],
+			Options:    strings.Split(fields[3], ","),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return mounts, nil
+}
+
+// FindMount returns the mount backing the absolute path, which is the one with the longest matching mount point.
+// When a mount point is mounted over multiple times, the last one wins.
+func FindMount(mounts []Mount, absPath string) *Mount {
+	absPath = filepath.Clean(absPath)
+
+	var found *Mount
+	for i, mount := range mounts {
+		mountPoint := filepath.Clean(mount.MountPoint)
+		if mountPoint != "/" && absPath != mountPoint && !strings.HasPrefix(absPath, mountPoint+"/") {
+			continue
+		}
+		if found == nil || len(mountPoint) >= len(filepath.Clean(found.MountPoint)) {
+			found = &mounts[i]
+		}
+	}
+	return found
+}
+
+// unescapeMountField decodes octal escapes like \040 (space) used in /proc/mounts.
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if n, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		sb.WriteByte(field[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,302 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+// Constants of the CometBFT SecretConnection (p2p/conn/secret_connection.go).
+const (
+	secretConnDataLenSize    = 4
+	secretConnDataMaxSize    = 1024
+	secretConnTotalFrameSize = secretConnDataMaxSize + secretConnDataLenSize
+	secretConnMaxMsgSize     = 1024 * 1024
+
+	secretConnTranscriptLabel    = "TENDERMINT_SECRET_CONNECTION_TRANSCRIPT_HASH"
+	secretConnKeyAndChallengeGen = "TENDERMINT_SECRET_CONNECTION_KEY_AND_CHALLENGE_GEN"
+)
+
+var (
+	labelEphemeralLowerPublicKey = []byte("EPHEMERAL_LOWER_PUBLIC_KEY")
+	labelEphemeralUpperPublicKey = []byte("EPHEMERAL_UPPER_PUBLIC_KEY")
+	labelDHSecret                = []byte("DH_SECRET")
+	labelSecretConnectionMac     = []byte("SECRET_CONNECTION_MAC")
+)
+
+// SecretConnection is the encrypted stream after the CometBFT SecretConnection handshake.
+type SecretConnection struct {
+	conn      io.ReadWriter
+	recvAead  cipher.AEAD
+	sendAead  cipher.AEAD
+	recvNonce [chacha20poly1305.NonceSize]byte
+	sendNonce [chacha20poly1305.NonceSize]byte
+	recvBuf   []byte
+
+	RemotePubKey ed25519.PublicKey
+}
+
+// MakeSecretConnection performs the SecretConnection handshake over conn, authenticated by locPrivKey,
+// the remote public key is verified by its signature on the challenge.
+// Node ID of the remote is the first 20 bytes of SHA256 of RemotePubKey.
+func MakeSecretConnection(conn io.ReadWriter, locPrivKey ed25519.PrivateKey) (*SecretConnection, error) {
+	locEphPub, locEphPriv, err := genEphemeralKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	remEphPub, err := shareEphemeralPubKey(conn, locEphPub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to exchange ephemeral key")
+	}
+
+	loEphPub, hiEphPub := locEphPub, remEphPub
+	locIsLeast := bytes.Compare(locEphPub, remEphPub) < 0
+	if !locIsLeast {
+		loEphPub, hiEphPub = remEphPub, locEphPub
+	}
+
+	transcript := merlin.NewTranscript(secretConnTranscriptLabel)
+	transcript.AppendMessage(labelEphemeralLowerPublicKey, loEphPub)
+	transcript.AppendMessage(labelEphemeralUpperPublicKey, hiEphPub)
+
+	dhSecret, err := curve25519.X25519(locEphPriv, remEphPub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute shared secret")
+	}
+	transcript.AppendMessage(labelDHSecret, dhSecret)
+
+	recvSecret, sendSecret, err := deriveSecretConnSecrets(dhSecret, locIsLeast)
+	if err != nil {
+		return nil, err
+	}
+	challenge := transcript.ExtractBytes(labelSecretConnectionMac, 32)
+
+	sc := &SecretConnection{conn: conn}
+	if sc.recvAead, err = chacha20poly1305.New(recvSecret); err != nil {
+		return nil, err
+	}
+	if sc.sendAead, err = chacha20poly1305.New(sendSecret); err != nil {
+		return nil, err
+	}
+
+	remPubKey, remSignature, err := shareAuthSignature(sc, locPrivKey.Public().(ed25519.PublicKey), ed25519.Sign(locPrivKey, challenge))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to exchange auth signature")
+	}
+	if !ed25519.Verify(remPubKey, challenge, remSignature) {
+		return nil, fmt.Errorf("remote signature on the challenge is invalid")
+	}
+	sc.RemotePubKey = remPubKey
+
+	return sc, nil
+}
+
+// Write encrypts data into frames.
+func (sc *SecretConnection) Write(data []byte) (n int, err error) {
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > secretConnDataMaxSize {
+			chunk = data[:secretConnDataMaxSize]
+		}
+		data = data[len(chunk):]
+
+		frame := make([]byte, secretConnTotalFrameSize)
+		binary.LittleEndian.PutUint32(frame, uint32(len(chunk)))
+		copy(frame[secretConnDataLenSize:], chunk)
+
+		sealedFrame := sc.sendAead.Seal(nil, sc.sendNonce[:], frame, nil)
+		incrSecretConnNonce(&sc.sendNonce)
+		if _, err := sc.conn.Write(sealedFrame); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+	}
+	return n, nil
+}
+
+// Read decrypts the next frame when the buffer is drained.
+func (sc *SecretConnection) Read(data []byte) (n int, err error) {
+	if len(sc.recvBuf) == 0 {
+		sealedFrame := make([]byte, secretConnTotalFrameSize+sc.recvAead.Overhead())
+		if _, err := io.ReadFull(sc.conn, sealedFrame); err != nil {
+			return 0, err
+		}
+		frame, err := sc.recvAead.Open(nil, sc.recvNonce[:], sealedFrame, nil)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to decrypt frame")
+		}
+		incrSecretConnNonce(&sc.recvNonce)
+
+		chunkLength := binary.LittleEndian.Uint32(frame)
+		if chunkLength > secretConnDataMaxSize {
+			return 0, fmt.Errorf("chunk length %d is greater than %d", chunkLength, secretConnDataMaxSize)
+		}
+		sc.recvBuf = frame[secretConnDataLenSize : secretConnDataLenSize+chunkLength]
+	}
+
+	n = copy(data, sc.recvBuf)
+	sc.recvBuf = sc.recvBuf[n:]
+	return n, nil
+}
+
+func genEphemeralKeys() (pub, priv []byte, err error) {
+	priv = make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(priv); err != nil {
+		return nil, nil, err
+	}
+	pub, err = curve25519.X25519(priv, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub, priv, nil
+}
+
+// shareEphemeralPubKey sends and receives ephemeral key as length-delimited protobuf BytesValue,
+// sending is done concurrently since both sides send first.
+func shareEphemeralPubKey(conn io.ReadWriter, locEphPub []byte) ([]byte, error) {
+	sent := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(delimitedProto(protoBytesField(1, locEphPub)))
+		sent <- err
+	}()
+
+	msg, err := readDelimitedProto(conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := <-sent; err != nil {
+		return nil, err
+	}
+
+	fields, err := parseProtoBytesFields(msg)
+	if err != nil {
+		return nil, err
+	}
+	remEphPub := fields[1]
+	if len(remEphPub) != curve25519.PointSize {
+		return nil, fmt.Errorf("ephemeral key must be %d bytes, got %d", curve25519.PointSize, len(remEphPub))
+	}
+	return remEphPub, nil
+}
+
+// shareAuthSignature sends and receives AuthSigMessage{pub_key: PublicKey{ed25519}, sig} over the secret connection.
+func shareAuthSignature(sc *SecretConnection, pubKey ed25519.PublicKey, signature []byte) (ed25519.PublicKey, []byte, error) {
+	sent := make(chan error, 1)
+	go func() {
+		msg := append(protoBytesField(1, protoBytesField(1, pubKey)), protoBytesField(2, signature)...)
+		_, err := sc.Write(delimitedProto(msg))
+		sent <- err
+	}()
+
+	msg, err := readDelimitedProto(sc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := <-sent; err != nil {
+		return nil, nil, err
+	}
+
+	fields, err := parseProtoBytesFields(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKeyFields, err := parseProtoBytesFields(fields[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	remPubKey := pubKeyFields[1]
+	if len(remPubKey) != ed25519.PublicKeySize {
+		return nil, nil, fmt.Errorf("remote node key is not ed25519")
+	}
+	return remPubKey, fields[2], nil
+}
+
+func deriveSecretConnSecrets(dhSecret []byte, locIsLeast bool) (recvSecret, sendSecret []byte, err error) {
+	res := make([]byte, 2*chacha20poly1305.KeySize+32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, dhSecret, nil, []byte(secretConnKeyAndChallengeGen)), res); err != nil {
+		return nil, nil, err
+	}
+	first, second := res[:chacha20poly1305.KeySize], res[chacha20poly1305.KeySize:2*chacha20poly1305.KeySize]
+	if locIsLeast {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+// incrSecretConnNonce increases the little-endian counter after the first 4 bytes of the nonce.
+func incrSecretConnNonce(nonce *[chacha20poly1305.NonceSize]byte) {
+	counter := binary.LittleEndian.Uint64(nonce[4:])
+	binary.LittleEndian.PutUint64(nonce[4:], counter+1)
+}
+
+// protoBytesField encodes a length-delimited protobuf field.
+func protoBytesField(fieldNumber int, value []byte) []byte {
+	bz := binary.AppendUvarint(nil, uint64(fieldNumber<<3|2))
+	bz = binary.AppendUvarint(bz, uint64(len(value)))
+	return append(bz, value...)
+}
+
+func delimitedProto(msg []byte) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(msg))), msg...)
+}
+
+// readDelimitedProto reads a length-delimited message without reading ahead, the rest of the stream is encrypted.
+func readDelimitedProto(r io.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(byteReader{r})
+	if err != nil {
+		return nil, err
+	}
+	if length > secretConnMaxMsgSize {
+		return nil, fmt.Errorf("message size %d exceeds %d", length, secretConnMaxMsgSize)
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parseProtoBytesFields parses a message which only contains length-delimited fields.
+func parseProtoBytesFields(msg []byte) (map[int][]byte, error) {
+	fields := make(map[int][]byte)
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed protobuf tag")
+		}
+		msg = msg[n:]
+		if tag&7 != 2 {
+			return nil, fmt.Errorf("unexpected protobuf wire type %d", tag&7)
+		}
+		length, n := binary.Uvarint(msg)
+		if n <= 0 || uint64(len(msg)-n) < length {
+			return nil, fmt.Errorf("malformed protobuf field")
+		}
+		fields[int(tag>>3)] = msg[n : n+int(length)]
+		msg = msg[n+int(length):]
+	}
+	return fields, nil
+}
+
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r.Reader, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
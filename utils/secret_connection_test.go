@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestMakeSecretConnection(t *testing.T) {
+	pub1, priv1, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub2, priv2, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn1, conn2 := net.Pipe()
+	defer conn1.Close()
+	defer conn2.Close()
+
+	type handshakeResult struct {
+		sc  *SecretConnection
+		err error
+	}
+	results := make(chan handshakeResult, 1)
+	go func() {
+		sc, err := MakeSecretConnection(conn2, priv2)
+		results <- handshakeResult{sc: sc, err: err}
+	}()
+
+	sc1, err := MakeSecretConnection(conn1, priv1)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	result := <-results
+	if result.err != nil {
+		t.Fatalf("handshake of the other side failed: %v", result.err)
+	}
+	sc2 := result.sc
+
+	if !bytes.Equal(sc1.RemotePubKey, pub2) {
+		t.Errorf("RemotePubKey = %x, want %x", sc1.RemotePubKey, pub2)
+	}
+	if !bytes.Equal(sc2.RemotePubKey, pub1) {
+		t.Errorf("RemotePubKey of the other side = %x, want %x", sc2.RemotePubKey, pub1)
+	}
+
+	// larger than a frame, to cover splitting into multiple frames
+	msg := bytes.Repeat([]byte("node-setup-check"), 200)
+	sent := make(chan error, 1)
+	go func() {
+		_, err := sc1.Write(msg)
+		sent <- err
+	}()
+	got := make([]byte, len(msg))
+	if _, err := io.ReadFull(sc2, got); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if err := <-sent; err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("read data does not match the written data")
+	}
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var regexpSemver = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(-([0-9A-Za-z.-]+))?(\+[0-9A-Za-z.-]+)?$`)
+
+// regexpGitDescribeSuffix matches the suffix appended by `git describe --tags` when the build is made
+// on commits after the tag, e.g. 1.2.1-3-gabcdef0.
+var regexpGitDescribeSuffix = regexp.MustCompile(`-\d+-g[\da-f]+(-dirty)?$`)
+
+type Semver struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	PreRelease string
+}
+
+// ParseSemver parses a semantic version, with or without the "v" prefix.
+// Build metadata is ignored.
+// Version produced by `git describe --tags` on commits after a tag is treated as a post-release of that tag,
+// so the suffix is dropped and the version is considered equals to the tag.
+func ParseSemver(version string) (semver Semver, ok bool) {
+	version = strings.TrimSpace(version)
+	version = regexpGitDescribeSuffix.ReplaceAllString(version, "")
+
+	matches := regexpSemver.FindStringSubmatch(version)
+	if matches == nil {
+		return
+	}
+
+	var err error
+	if semver.Major, err = strconv.ParseUint(matches[1], 10, 64); err != nil {
+		return
+	}
+	if semver.Minor, err = strconv.ParseUint(matches[2], 10, 64); err != nil {
+		return
+	}
+	if semver.Patch, err = strconv.ParseUint(matches[3], 10, 64); err != nil {
+		return
+	}
+	semver.PreRelease = matches[5]
+
+	ok = true
+	return
+}
+
+// Compare returns -1 if s < other, 0 if s == other and 1 if s > other, following semver precedence rules.
+func (s Semver) Compare(other Semver) int {
+	if c := compareUint64(s.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareUint64(s.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint64(s.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// a version without pre-release has higher precedence
+	if s.PreRelease == "" && other.PreRelease == "" {
+		return 0
+	}
+	if s.PreRelease == "" {
+		return 1
+	}
+	if other.PreRelease == "" {
+		return -1
+	}
+
+	left := strings.Split(s.PreRelease, ".")
+	right := strings.Split(other.PreRelease, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if c := comparePreReleaseIdentifier(left[i], right[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareUint64(uint64(len(left)), uint64(len(right)))
+}
+
+func comparePreReleaseIdentifier(left, right string) int {
+	leftNum, errLeft := strconv.ParseUint(left, 10, 64)
+	rightNum, errRight := strconv.ParseUint(right, 10, 64)
+
+	switch {
+	case errLeft == nil && errRight == nil:
+		return compareUint64(leftNum, rightNum)
+	case errLeft == nil:
+		// numeric identifiers have lower precedence than alphanumeric identifiers
+		return -1
+	case errRight == nil:
+		return 1
+	default:
+		return strings.Compare(left, right)
+	}
+}
+
+func compareUint64(left, right uint64) int {
+	if left < right {
+		return -1
+	}
+	if left > right {
+		return 1
+	}
+	return 0
+}
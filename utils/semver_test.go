@@ -0,0 +1,69 @@
+package utils
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		version string
+		want    Semver
+		wantOk  bool
+	}{
+		{version: "1.2.3", want: Semver{Major: 1, Minor: 2, Patch: 3}, wantOk: true},
+		{version: "v1.2.3", want: Semver{Major: 1, Minor: 2, Patch: 3}, wantOk: true},
+		{version: " v1.2.3\n", want: Semver{Major: 1, Minor: 2, Patch: 3}, wantOk: true},
+		{version: "1.2.3-rc.1", want: Semver{Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.1"}, wantOk: true},
+		{version: "1.2.3+build.5", want: Semver{Major: 1, Minor: 2, Patch: 3}, wantOk: true},
+		{version: "1.2.3-3-gabcdef0", want: Semver{Major: 1, Minor: 2, Patch: 3}, wantOk: true},
+		{version: "1.2.3-3-gabcdef0-dirty", want: Semver{Major: 1, Minor: 2, Patch: 3}, wantOk: true},
+		{version: "1.2.3-rc.1-3-gabcdef0", want: Semver{Major: 1, Minor: 2, Patch: 3, PreRelease: "rc.1"}, wantOk: true},
+		{version: "main", wantOk: false},
+		{version: "dev", wantOk: false},
+		{version: "", wantOk: false},
+		{version: "1.2", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, ok := ParseSemver(tt.version)
+			if ok != tt.wantOk {
+				t.Fatalf("ParseSemver(%q) ok = %t, want %t", tt.version, ok, tt.wantOk)
+			}
+			if ok && got != tt.want {
+				t.Fatalf("ParseSemver(%q) = %+v, want %+v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		left  string
+		right string
+		want  int
+	}{
+		{left: "1.2.3", right: "1.2.3", want: 0},
+		{left: "1.2.3", right: "1.2.4", want: -1},
+		{left: "1.3.0", right: "1.2.9", want: 1},
+		{left: "2.0.0", right: "1.99.99", want: 1},
+		{left: "1.2.3-rc.1", right: "1.2.3", want: -1},
+		{left: "1.2.3-alpha", right: "1.2.3-alpha.1", want: -1},
+		{left: "1.2.3-alpha.1", right: "1.2.3-alpha.beta", want: -1},
+		{left: "1.2.3-rc.2", right: "1.2.3-rc.10", want: -1},
+		{left: "1.2.3-beta", right: "1.2.3-alpha", want: 1},
+		{left: "1.2.3-3-gabcdef0", right: "1.2.3", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.left+"_"+tt.right, func(t *testing.T) {
+			left, ok := ParseSemver(tt.left)
+			if !ok {
+				t.Fatalf("failed to parse %q", tt.left)
+			}
+			right, ok := ParseSemver(tt.right)
+			if !ok {
+				t.Fatalf("failed to parse %q", tt.right)
+			}
+			if got := left.Compare(right); got != tt.want {
+				t.Fatalf("%s compare %s = %d, want %d", tt.left, tt.right, got, tt.want)
+			}
+		})
+	}
+}
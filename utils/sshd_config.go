@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SshdConfigSetting is an occurrence of a keyword in sshd_config.
+type SshdConfigSetting struct {
+	Value string
+	File  string
+	Line  int // 1-based
+}
+
+// SshdConfig is the global settings of sshd, keywords are lower-cased.
+// Settings within Match blocks are ignored since they apply conditionally.
+type SshdConfig struct {
+	MainFile string
+	settings map[string][]SshdConfigSetting
+}
+
+// Get returns the effective setting, for most keywords the first obtained value is used by sshd.
+func (c *SshdConfig) Get(keyword string) (SshdConfigSetting, bool) {
+	occurrences := c.settings[strings.ToLower(keyword)]
+	if len(occurrences) == 0 {
+		return SshdConfigSetting{}, false
+	}
+	return occurrences[0], true
+}
+
+// GetAll returns all occurrences of the keyword, for keywords which can be specified multiple times like Port.
+func (c *SshdConfig) GetAll(keyword string) []SshdConfigSetting {
+	return c.settings[strings.ToLower(keyword)]
+}
+
+// ReadSshdConfig reads sshd_config and the files included by Include directives, in the order sshd reads them.
+// Relative include paths are relative to the directory of the main file, like /etc/ssh.
+// Absolute include paths under /etc are resolved under etcRoot, to read from a fixture tree.
+func ReadSshdConfig(mainFile string, etcRoot string) (*SshdConfig, error) {
+	config := &SshdConfig{
+		MainFile: mainFile,
+		settings: make(map[string][]SshdConfigSetting),
+	}
+	if err := config.readFile(mainFile, filepath.Dir(mainFile), etcRoot, 0); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *SshdConfig) readFile(filePath string, baseDir string, etcRoot string, depth int) error {
+	if depth > 16 {
+		return fmt.Errorf("too deep Include at %s", filePath)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var inMatchBlock bool
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Keyword value, or Keyword=value
+		idx := strings.IndexAny(line, " \t=")
+		if idx < 0 {
+			continue
+		}
+		keyword := strings.ToLower(line[:idx])
+		value := strings.TrimSpace(line[idx:])
+		value = strings.Trim(strings.TrimSpace(strings.TrimPrefix(value, "=")), `"`)
+
+		if keyword == "match" {
+			// Match block lasts until another Match line or the end of the file
+			inMatchBlock = !strings.EqualFold(value, "all")
+			continue
+		}
+		if inMatchBlock {
+			continue
+		}
+
+		if keyword == "include" {
+			for _, pattern := range strings.Fields(value) {
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(baseDir, pattern)
+				} else if strings.HasPrefix(pattern, "/etc/") {
+					pattern = filepath.Join(etcRoot, strings.TrimPrefix(pattern, "/etc/"))
+				}
+				matches, err := filepath.Glob(pattern)
+				if err != nil {
+					return fmt.Errorf("invalid Include pattern %q at %s:%d", pattern, filePath, lineNo)
+				}
+				sort.Strings(matches)
+				for _, match := range matches {
+					if err := c.readFile(match, baseDir, etcRoot, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		c.settings[keyword] = append(c.settings[keyword], SshdConfigSetting{
+			Value: value,
+			File:  filePath,
+			Line:  lineNo,
+		})
+	}
+
+	return scanner.Err()
+}
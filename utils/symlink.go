@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LinkInfo checks if the path itself is a symlink, without following it, and resolves the final target.
+// Target is empty if the path is not a symlink, or if the symlink is broken.
+func LinkInfo(path string) (isSymlink bool, target string, err error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return
+	}
+
+	isSymlink = true
+	target, err = filepath.EvalSymlinks(path)
+	if os.IsNotExist(err) {
+		// broken symlink
+		target, err = "", nil
+	}
+	return
+}
+
+// ParentDirs returns the parent directories of the absolute path, from the root down to the closest parent.
+func ParentDirs(absPath string) []string {
+	var parents []string
+	for dir := filepath.Dir(filepath.Clean(absPath)); ; dir = filepath.Dir(dir) {
+		parents = append([]string{dir}, parents...)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return parents
+}
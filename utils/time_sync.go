@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeSyncStatus is the clock synchronization status, fields are nil if not reported.
+type TimeSyncStatus struct {
+	Synchronized *bool
+	Offset       *time.Duration
+}
+
+var chronyTrackingLogLineRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\s`)
+
+// ParseTimeSyncStatus parses the output of `timedatectl show`, `timedatectl timesync-status`, `chronyc tracking`
+// or the content of chrony tracking.log.
+func ParseTimeSyncStatus(content string) (*TimeSyncStatus, error) {
+	status := &TimeSyncStatus{}
+	setSynchronized := func(synchronized bool) {
+		status.Synchronized = &synchronized
+	}
+	setOffset := func(offset time.Duration) {
+		status.Offset = &offset
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// chrony tracking.log:
+		// Date (UTC) Time     IP Address   St   Freq ppm   Skew ppm     Offset L Co  Offset sd Rem. corr. ...
+		if chronyTrackingLogLineRegex.MatchString(line) {
+			fields := strings.Fields(line)
+			if len(fields) < 7 {
+				return nil, fmt.Errorf("malformed chrony tracking log line: %s", line)
+			}
+			seconds, err := strconv.ParseFloat(fields[6], 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed offset in chrony tracking log line: %s", line)
+			}
+			setOffset(secondsToDuration(seconds))
+			continue
+		}
+
+		// timedatectl show: key=value
+		if key, value, ok := strings.Cut(line, "="); ok && !strings.Contains(key, " ") {
+			if key == "NTPSynchronized" {
+				setSynchronized(value == "yes")
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "Offset":
+			// timedatectl timesync-status: Offset: -1.234ms
+			offset, err := time.ParseDuration(strings.TrimPrefix(value, "+"))
+			if err != nil {
+				return nil, fmt.Errorf("malformed offset: %s", value)
+			}
+			setOffset(offset)
+		case "System time":
+			// chronyc tracking: System time     : 0.000012345 seconds fast of NTP time
+			fields := strings.Fields(value)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("malformed system time: %s", value)
+			}
+			seconds, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed system time: %s", value)
+			}
+			if fields[2] == "slow" {
+				seconds = -seconds
+			}
+			setOffset(secondsToDuration(seconds))
+		case "Leap status":
+			// chronyc tracking
+			setSynchronized(value != "Not synchronised")
+		}
+	}
+
+	if status.Synchronized == nil && status.Offset == nil {
+		return nil, fmt.Errorf("unrecognized time sync status, supported: output of `timedatectl show`, `timedatectl timesync-status`, `chronyc tracking` or chrony tracking.log")
+	}
+
+	return status, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}